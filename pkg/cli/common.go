@@ -41,7 +41,7 @@ func InitFS(fs *pflag.FlagSet) {
 func PrintFlagSet(fs *pflag.FlagSet) string {
 	s := ""
 	fs.VisitAll(func(f *pflag.Flag) {
-		s += fmt.Sprintf("--%s: %s\n", f.Name, f.Value.String())
+		s += fmt.Sprintf("--%s: %s\n", f.Name, redact(f, f.Value.String()))
 	})
 
 	return s
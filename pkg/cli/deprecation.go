@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/neee333ko/component-base/pkg/version"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+	"github.com/spf13/pflag"
+)
+
+// DeprecatedAnnotation is set on every flag registered by FlagDeprecations,
+// its value holds the deprecation message.
+const DeprecatedAnnotation = "cli.deprecated"
+
+// TranslateFunc converts a value given to a deprecated flag into a value
+// accepted by its replacement.
+type TranslateFunc func(value string) (string, error)
+
+// DeprecatedFlag describes a flag which is renamed or retired.
+type DeprecatedFlag struct {
+	// Name is the old flag name, it keeps working until RemovedIn.
+	Name string
+	// Replacement is the flag the value is forwarded to. Empty means the flag
+	// is retired without a successor.
+	Replacement string
+	// RemovedIn is the release from which using the flag fails.
+	// Empty means the removal is not scheduled yet.
+	RemovedIn string
+	// Translate converts the old value into the replacement value.
+	Translate TranslateFunc
+}
+
+func (df *DeprecatedFlag) Message() string {
+	msg := fmt.Sprintf("flag --%s has been deprecated", df.Name)
+	if df.Replacement != "" {
+		msg += fmt.Sprintf(", use --%s instead", df.Replacement)
+	}
+
+	if df.RemovedIn != "" {
+		msg += fmt.Sprintf(", it will be removed in %s", df.RemovedIn)
+	}
+
+	return msg
+}
+
+func (df *DeprecatedFlag) removed() bool {
+	if df.RemovedIn == "" {
+		return false
+	}
+
+	current, err := version.ParseSemantic(version.Get().GitVersion)
+	if err != nil {
+		return false
+	}
+
+	return current.AtLeast(version.MustParseSemantic(df.RemovedIn))
+}
+
+// FlagDeprecations is a registry of deprecated flags.
+type FlagDeprecations struct {
+	mu    sync.Mutex
+	order []string
+	flags map[string]*DeprecatedFlag
+}
+
+func NewFlagDeprecations() *FlagDeprecations {
+	return &FlagDeprecations{
+		flags: make(map[string]*DeprecatedFlag),
+	}
+}
+
+func (d *FlagDeprecations) Register(flags ...DeprecatedFlag) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range flags {
+		df := flags[i]
+
+		if df.Name == "" {
+			return errors.New("deprecated flag must have a name")
+		}
+
+		if _, ok := d.flags[df.Name]; ok {
+			return errors.Errorf("deprecated flag --%s already registered", df.Name)
+		}
+
+		if df.RemovedIn != "" {
+			if _, err := version.ParseSemantic(df.RemovedIn); err != nil {
+				return errors.Wrapf(err, "invalid removal version of flag --%s", df.Name)
+			}
+		}
+
+		d.order = append(d.order, df.Name)
+		d.flags[df.Name] = &df
+	}
+
+	return nil
+}
+
+func (d *FlagDeprecations) MustRegister(flags ...DeprecatedFlag) {
+	if err := d.Register(flags...); err != nil {
+		panic(err)
+	}
+}
+
+// AddFlags adds the old flag names to fs. It must be called after the
+// replacement flags are defined. Flags with a replacement forward their value
+// to it, retired flags keep their own value. All of them are hidden from help.
+func (d *FlagDeprecations) AddFlags(fs *pflag.FlagSet) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, name := range d.order {
+		df := d.flags[name]
+		existing := fs.Lookup(df.Name)
+
+		switch {
+		case df.Replacement != "":
+			replacement := fs.Lookup(df.Replacement)
+			if replacement == nil {
+				return errors.Errorf("replacement flag --%s of --%s is not defined", df.Replacement, df.Name)
+			}
+
+			if existing != nil {
+				return errors.Errorf("deprecated flag --%s is still defined", df.Name)
+			}
+
+			fs.Var(&deprecatedValue{flag: df, target: replacement.Value}, df.Name, df.Message())
+			fs.Lookup(df.Name).NoOptDefVal = replacement.NoOptDefVal
+		case existing != nil:
+			existing.Value = &deprecatedValue{flag: df, target: existing.Value}
+		default:
+			fs.Var(&deprecatedValue{flag: df, target: new(retiredValue)}, df.Name, df.Message())
+		}
+
+		_ = fs.SetAnnotation(df.Name, DeprecatedAnnotation, []string{df.Message()})
+		_ = fs.MarkHidden(df.Name)
+	}
+
+	return nil
+}
+
+type deprecatedValue struct {
+	flag   *DeprecatedFlag
+	target pflag.Value
+	once   sync.Once
+}
+
+var _ pflag.Value = &deprecatedValue{}
+
+func (v *deprecatedValue) String() string {
+	return v.target.String()
+}
+
+func (v *deprecatedValue) Type() string {
+	return v.target.Type()
+}
+
+func (v *deprecatedValue) Set(s string) error {
+	if v.flag.removed() {
+		msg := fmt.Sprintf("flag --%s has been removed in %s", v.flag.Name, v.flag.RemovedIn)
+		if v.flag.Replacement != "" {
+			msg += fmt.Sprintf(", use --%s instead", v.flag.Replacement)
+		}
+
+		return errors.New(msg)
+	}
+
+	v.once.Do(func() {
+		log.Warnf("%s\n", v.flag.Message())
+	})
+
+	if v.flag.Translate != nil {
+		translated, err := v.flag.Translate(s)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for deprecated flag --%s", v.flag.Name)
+		}
+
+		s = translated
+	}
+
+	return v.target.Set(s)
+}
+
+// retiredValue keeps the value of a retired flag which has no replacement.
+type retiredValue string
+
+func (v *retiredValue) String() string     { return string(*v) }
+func (v *retiredValue) Type() string       { return "string" }
+func (v *retiredValue) Set(s string) error { *v = retiredValue(s); return nil }
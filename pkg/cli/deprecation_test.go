@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/neee333ko/component-base/pkg/version"
+	"github.com/spf13/pflag"
+)
+
+func TestFlagDeprecations(t *testing.T) {
+	defer func(v string) { version.GitVersion = v }(version.GitVersion)
+
+	tests := []struct {
+		current   string
+		args      []string
+		wantErr   bool
+		wantAddr  string
+		wantLevel string
+	}{
+		{
+			current:   "v1.0.0",
+			args:      []string{"--bind-address=1.2.3.4", "--verbose=2"},
+			wantAddr:  "1.2.3.4",
+			wantLevel: "debug",
+		},
+		{
+			current:   "v1.1.9",
+			args:      []string{"--address=5.6.7.8"},
+			wantAddr:  "5.6.7.8",
+			wantLevel: "info",
+		},
+		{
+			current: "v1.2.0",
+			args:    []string{"--bind-address=1.2.3.4"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		version.GitVersion = tt.current
+
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		addr := fs.String("address", "0.0.0.0", "")
+		level := fs.String("log-level", "info", "")
+
+		d := NewFlagDeprecations()
+		d.MustRegister(
+			DeprecatedFlag{Name: "bind-address", Replacement: "address", RemovedIn: "v1.2.0"},
+			DeprecatedFlag{Name: "verbose", Replacement: "log-level", Translate: func(s string) (string, error) {
+				if s == "2" {
+					return "debug", nil
+				}
+
+				return "info", nil
+			}},
+		)
+
+		if err := d.AddFlags(fs); err != nil {
+			t.Fatalf("AddFlags has an error: %v\n", err)
+		}
+
+		if !fs.Lookup("bind-address").Hidden {
+			t.Errorf("deprecated flag should be hidden\n")
+		}
+
+		err := fs.Parse(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse has an error: want error:%v got:%v\n", tt.wantErr, err)
+		}
+
+		if tt.wantErr {
+			if err != nil && !strings.Contains(err.Error(), "removed in v1.2.0") {
+				t.Errorf("unexpected error message: %v\n", err)
+			}
+
+			continue
+		}
+
+		if *addr != tt.wantAddr || *level != tt.wantLevel {
+			t.Errorf("deprecated flag was not forwarded: want:%s,%s got:%s,%s\n", tt.wantAddr, tt.wantLevel, *addr, *level)
+		}
+	}
+}
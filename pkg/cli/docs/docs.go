@@ -0,0 +1,190 @@
+// Package docs generates man pages and markdown reference pages from the
+// flag sets of a command tree.
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neee333ko/component-base/pkg/cli"
+	"github.com/neee333ko/component-base/pkg/version"
+	"github.com/neee333ko/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatMan      = "man"
+)
+
+// Command describes a command and its subcommands for documentation.
+type Command struct {
+	Name     string
+	Short    string
+	Long     string
+	Example  string
+	FlagSets cli.NamedFlagSets
+	Commands []*Command
+
+	parent *Command
+}
+
+func (c *Command) AddCommand(cmds ...*Command) {
+	for _, cmd := range cmds {
+		cmd.parent = c
+		c.Commands = append(c.Commands, cmd)
+	}
+}
+
+func (c *Command) CommandPath() string {
+	if c.parent == nil {
+		return c.Name
+	}
+
+	return c.parent.CommandPath() + " " + c.Name
+}
+
+func (c *Command) sortedCommands() []*Command {
+	cmds := make([]*Command, len(c.Commands))
+	copy(cmds, c.Commands)
+
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+
+	return cmds
+}
+
+// Generate writes the docs of cmd and all its subcommands into dir, it is meant
+// to back a hidden `docs` subcommand.
+func Generate(cmd *Command, format string, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatMarkdown:
+		return GenMarkdownTree(cmd, dir)
+	case FormatMan:
+		return GenManTree(cmd, nil, dir)
+	default:
+		return errors.Errorf("unsupported docs format %q, supported formats: %s, %s", format, FormatMarkdown, FormatMan)
+	}
+}
+
+type docFlag struct {
+	name       string
+	shorthand  string
+	typ        string
+	defValue   string
+	usage      string
+	deprecated string
+}
+
+type docSection struct {
+	name  string
+	flags []docFlag
+}
+
+// sections collects the visible flags per section plus a trailing section of
+// deprecated flags, which are hidden from help but still documented.
+func sections(cmd *Command) []docSection {
+	result := make([]docSection, 0, len(cmd.FlagSets.Order)+1)
+	deprecated := docSection{name: "deprecated"}
+
+	for _, name := range cmd.FlagSets.Order {
+		section := docSection{name: name}
+
+		cmd.FlagSets.FlagSets[name].VisitAll(func(f *pflag.Flag) {
+			df := docFlag{
+				name:      f.Name,
+				shorthand: f.Shorthand,
+				typ:       f.Value.Type(),
+				defValue:  defaultValue(f),
+				usage:     f.Usage,
+			}
+
+			if msg, ok := f.Annotations[cli.DeprecatedAnnotation]; ok && len(msg) > 0 {
+				df.deprecated = msg[0]
+				deprecated.flags = append(deprecated.flags, df)
+
+				return
+			}
+
+			if f.Deprecated != "" {
+				df.deprecated = fmt.Sprintf("flag --%s has been deprecated, %s", f.Name, f.Deprecated)
+				deprecated.flags = append(deprecated.flags, df)
+
+				return
+			}
+
+			if f.Hidden {
+				return
+			}
+
+			section.flags = append(section.flags, df)
+		})
+
+		if len(section.flags) > 0 {
+			result = append(result, section)
+		}
+	}
+
+	if len(deprecated.flags) > 0 {
+		result = append(result, deprecated)
+	}
+
+	return result
+}
+
+func defaultValue(f *pflag.Flag) string {
+	if cli.IsSensitive(f) && f.DefValue != "" {
+		return "******"
+	}
+
+	if f.Value.Type() == "string" {
+		return fmt.Sprintf("%q", f.DefValue)
+	}
+
+	return f.DefValue
+}
+
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func buildDate() time.Time {
+	if t, err := time.Parse(time.RFC3339, version.Get().BuildDate); err == nil {
+		return t
+	}
+
+	return time.Now()
+}
+
+func versionLine() string {
+	info := version.Get()
+
+	return fmt.Sprintf("%s (commit %s, built %s)", info.GitVersion, info.GitCommit, info.BuildDate)
+}
+
+func basename(cmd *Command, sep string) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", sep)
+}
+
+func genTree(cmd *Command, dir string, ext string, sep string, gen func(cmd *Command, path string) error) error {
+	for _, c := range cmd.Commands {
+		c.parent = cmd
+
+		if err := genTree(c, dir, ext, sep, gen); err != nil {
+			return err
+		}
+	}
+
+	return gen(cmd, filepath.Join(dir, basename(cmd, sep)+ext))
+}
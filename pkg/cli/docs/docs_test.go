@@ -0,0 +1,85 @@
+package docs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/neee333ko/component-base/pkg/cli"
+)
+
+func newTestCommand() *Command {
+	root := &Command{Name: "iam-apiserver", Short: "IAM API server"}
+	fs := root.FlagSets.FlagSet("generic")
+	fs.StringP("bind-address", "b", "0.0.0.0", "The IP address to listen on.")
+	fs.String("secret-key", "s3cr3t", "The secret | key.")
+	_ = cli.MarkSensitive(fs, "secret-key")
+
+	d := cli.NewFlagDeprecations()
+	d.MustRegister(cli.DeprecatedFlag{Name: "address", Replacement: "bind-address", RemovedIn: "v2.0.0"})
+	_ = d.AddFlags(fs)
+
+	root.AddCommand(&Command{Name: "migrate", Short: "Run database migrations"})
+
+	return root
+}
+
+func TestGenMarkdown(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := GenMarkdown(newTestCommand(), buf); err != nil {
+		t.Fatalf("GenMarkdown has an error: %v\n", err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"## iam-apiserver",
+		"#### Generic flags",
+		"| `-b`, `--bind-address` | string | \"0.0.0.0\" | The IP address to listen on. |",
+		"The secret \\| key.",
+		"#### Deprecated flags",
+		"flag --address has been deprecated, use --bind-address instead, it will be removed in v2.0.0",
+		"[iam-apiserver migrate](iam-apiserver_migrate.md)",
+		"###### Auto generated by iam-apiserver v0.0.0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("GenMarkdown misses %q:\n%s\n", want, out)
+		}
+	}
+
+	if strings.Contains(out, "s3cr3t") {
+		t.Errorf("GenMarkdown leaked a sensitive default:\n%s\n", out)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		format string
+		files  []string
+		want   string
+	}{
+		{format: FormatMarkdown, files: []string{"iam-apiserver.md", "iam-apiserver_migrate.md"}, want: "## iam-apiserver migrate"},
+		{format: FormatMan, files: []string{"iam-apiserver.1", "iam-apiserver-migrate.1"}, want: ".TH \"IAM-APISERVER-MIGRATE\" \"1\""},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+
+		if err := Generate(newTestCommand(), tt.format, dir); err != nil {
+			t.Fatalf("Generate(%s) has an error: %v\n", tt.format, err)
+		}
+
+		for _, file := range tt.files {
+			if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+				t.Errorf("Generate(%s) misses file %s\n", tt.format, file)
+			}
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dir, tt.files[1]))
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("Generate(%s) has an error: want:%q got:\n%s\n", tt.format, tt.want, data)
+		}
+	}
+}
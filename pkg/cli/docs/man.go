@@ -0,0 +1,151 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ManHeader is the .TH header of a man page, empty fields are derived from
+// the command and pkg/version.
+type ManHeader struct {
+	Title   string
+	Section string
+	Date    *time.Time
+	Source  string
+	Manual  string
+}
+
+func (h *ManHeader) fill(cmd *Command) *ManHeader {
+	header := ManHeader{}
+	if h != nil {
+		header = *h
+	}
+
+	if header.Title == "" {
+		header.Title = strings.ToUpper(basename(cmd, "-"))
+	}
+
+	if header.Section == "" {
+		header.Section = "1"
+	}
+
+	if header.Date == nil {
+		date := buildDate()
+		header.Date = &date
+	}
+
+	if header.Source == "" {
+		header.Source = versionLine()
+	}
+
+	return &header
+}
+
+func GenMan(cmd *Command, header *ManHeader, w io.Writer) error {
+	header = header.fill(cmd)
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, ".TH \"%s\" \"%s\" \"%s\" \"%s\" \"%s\"\n",
+		header.Title, header.Section, header.Date.Format("Jan 2006"), troff(header.Source), troff(header.Manual))
+	buf.WriteString(".nh\n.ad l\n")
+
+	fmt.Fprintf(buf, ".SH NAME\n%s \\- %s\n", troff(basename(cmd, "-")), troff(cmd.Short))
+	fmt.Fprintf(buf, ".SH SYNOPSIS\n\\fB%s\\fP [flags]\n", troff(cmd.CommandPath()))
+
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+
+	fmt.Fprintf(buf, ".SH DESCRIPTION\n%s\n", troff(description))
+
+	for _, section := range sections(cmd) {
+		fmt.Fprintf(buf, ".SH %s FLAGS\n", troff(strings.ToUpper(section.name)))
+
+		for _, f := range section.flags {
+			buf.WriteString(".TP\n")
+
+			if f.shorthand != "" {
+				fmt.Fprintf(buf, "\\fB\\-%s\\fP, ", troff(f.shorthand))
+			}
+
+			fmt.Fprintf(buf, "\\fB\\-\\-%s\\fP=\\fI%s\\fP\n", troff(f.name), troff(f.typ))
+
+			if section.name == "deprecated" {
+				fmt.Fprintf(buf, "%s\n", troff(f.deprecated))
+
+				continue
+			}
+
+			fmt.Fprintf(buf, "%s (default %s)\n", troff(f.usage), troff(f.defValue))
+		}
+	}
+
+	if cmd.Example != "" {
+		fmt.Fprintf(buf, ".SH EXAMPLE\n.PP\n.nf\n%s\n.fi\n", troff(cmd.Example))
+	}
+
+	if cmd.parent != nil || len(cmd.Commands) > 0 {
+		seeAlso := make([]string, 0)
+
+		if cmd.parent != nil {
+			seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s(%s)\\fP", troff(basename(cmd.parent, "-")), header.Section))
+		}
+
+		for _, c := range cmd.sortedCommands() {
+			seeAlso = append(seeAlso, fmt.Sprintf("\\fB%s(%s)\\fP", troff(basename(c, "-")), header.Section))
+		}
+
+		fmt.Fprintf(buf, ".SH SEE ALSO\n%s\n", strings.Join(seeAlso, ", "))
+	}
+
+	fmt.Fprintf(buf, ".SH HISTORY\nAuto generated by %s %s\n", troff(cmd.CommandPath()), troff(versionLine()))
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+func GenManTree(cmd *Command, header *ManHeader, dir string) error {
+	section := "1"
+	if header != nil && header.Section != "" {
+		section = header.Section
+	}
+
+	return genTree(cmd, dir, "."+section, "-", func(cmd *Command, path string) error {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		// every page derives its own title
+		var h *ManHeader
+		if header != nil {
+			copied := *header
+			copied.Title = ""
+			h = &copied
+		}
+
+		return GenMan(cmd, h, f)
+	})
+}
+
+// troff escapes s so that it is rendered literally by man.
+func troff(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = "\\&" + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,101 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func GenMarkdown(cmd *Command, w io.Writer) error {
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, "## %s\n\n", cmd.CommandPath())
+	fmt.Fprintf(buf, "%s\n\n", cmd.Short)
+
+	buf.WriteString("### Synopsis\n\n")
+
+	if cmd.Long != "" {
+		fmt.Fprintf(buf, "%s\n\n", cmd.Long)
+	}
+
+	fmt.Fprintf(buf, "```\n%s [flags]\n```\n\n", cmd.CommandPath())
+
+	if cmd.Example != "" {
+		fmt.Fprintf(buf, "### Examples\n\n```\n%s\n```\n\n", cmd.Example)
+	}
+
+	if secs := sections(cmd); len(secs) > 0 {
+		buf.WriteString("### Options\n\n")
+
+		for _, section := range secs {
+			fmt.Fprintf(buf, "#### %s flags\n\n", title(section.name))
+
+			if section.name == "deprecated" {
+				buf.WriteString("| Flag | Type | Deprecation |\n|------|------|-------------|\n")
+			} else {
+				buf.WriteString("| Flag | Type | Default | Description |\n|------|------|---------|-------------|\n")
+			}
+
+			for _, f := range section.flags {
+				name := "`--" + f.name + "`"
+				if f.shorthand != "" {
+					name = "`-" + f.shorthand + "`, " + name
+				}
+
+				if section.name == "deprecated" {
+					fmt.Fprintf(buf, "| %s | %s | %s |\n", name, f.typ, markdownCell(f.deprecated))
+
+					continue
+				}
+
+				fmt.Fprintf(buf, "| %s | %s | %s | %s |\n", name, f.typ, markdownCell(f.defValue), markdownCell(f.usage))
+			}
+
+			buf.WriteString("\n")
+		}
+	}
+
+	if cmd.parent != nil || len(cmd.Commands) > 0 {
+		buf.WriteString("### SEE ALSO\n\n")
+
+		if cmd.parent != nil {
+			fmt.Fprintf(buf, "* [%s](%s.md) - %s\n", cmd.parent.CommandPath(), basename(cmd.parent, "_"), cmd.parent.Short)
+		}
+
+		for _, c := range cmd.sortedCommands() {
+			fmt.Fprintf(buf, "* [%s](%s.md) - %s\n", c.CommandPath(), basename(c, "_"), c.Short)
+		}
+
+		buf.WriteString("\n")
+	}
+
+	fmt.Fprintf(buf, "###### Auto generated by %s %s\n", cmd.CommandPath(), versionLine())
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+func GenMarkdownTree(cmd *Command, dir string) error {
+	return genTree(cmd, dir, ".md", "_", func(cmd *Command, path string) error {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return GenMarkdown(cmd, f)
+	})
+}
+
+func markdownCell(s string) string {
+	if s == "" {
+		return " "
+	}
+
+	s = strings.ReplaceAll(s, "|", "\\|")
+
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// SensitiveAnnotation marks a flag whose value must never be printed.
+const SensitiveAnnotation = "cli.sensitive"
+
+const redacted = "******"
+
+const (
+	DumpFormatTable = "table"
+	DumpFormatJSON  = "json"
+	DumpFormatYAML  = "yaml"
+)
+
+func MarkSensitive(fs *pflag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if err := fs.SetAnnotation(name, SensitiveAnnotation, []string{"true"}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func IsSensitive(f *pflag.Flag) bool {
+	_, ok := f.Annotations[SensitiveAnnotation]
+
+	return ok
+}
+
+func redact(f *pflag.Flag, value string) string {
+	if value == "" || !IsSensitive(f) {
+		return value
+	}
+
+	return redacted
+}
+
+type DumpedFlag struct {
+	Name      string `json:"name" yaml:"name"`
+	Value     string `json:"value" yaml:"value"`
+	Default   string `json:"default" yaml:"default"`
+	Modified  bool   `json:"modified" yaml:"modified"`
+	Sensitive bool   `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+}
+
+type DumpedSection struct {
+	Name  string       `json:"name" yaml:"name"`
+	Flags []DumpedFlag `json:"flags" yaml:"flags"`
+}
+
+// ConfigDump is a snapshot of the effective configuration with sensitive
+// values masked.
+type ConfigDump struct {
+	Sections []DumpedSection `json:"sections" yaml:"sections"`
+}
+
+func DumpFlagSet(fs *pflag.FlagSet) []DumpedFlag {
+	flags := make([]DumpedFlag, 0)
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, ok := f.Annotations[DeprecatedAnnotation]; ok {
+			return
+		}
+
+		value := f.Value.String()
+
+		flags = append(flags, DumpedFlag{
+			Name:      f.Name,
+			Value:     redact(f, value),
+			Default:   redact(f, f.DefValue),
+			Modified:  value != f.DefValue,
+			Sensitive: IsSensitive(f),
+		})
+	})
+
+	return flags
+}
+
+func DumpNamedFlagSets(nfs *NamedFlagSets) *ConfigDump {
+	dump := &ConfigDump{Sections: make([]DumpedSection, 0, len(nfs.Order))}
+
+	for _, name := range nfs.Order {
+		dump.Sections = append(dump.Sections, DumpedSection{
+			Name:  name,
+			Flags: DumpFlagSet(nfs.FlagSets[name]),
+		})
+	}
+
+	return dump
+}
+
+func (d *ConfigDump) String() string {
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.Wrap = true
+
+	for i, section := range d.Sections {
+		if i > 0 {
+			table.AddRow("")
+		}
+
+		table.AddRow(strings.ToUpper(section.Name)+":", "", "")
+
+		for _, f := range section.Flags {
+			mark := ""
+			if f.Modified {
+				mark = "*"
+			}
+
+			table.AddRow(mark+"--"+f.Name, f.Value, fmt.Sprintf("(default %q)", f.Default))
+		}
+	}
+
+	return table.String()
+}
+
+func (d *ConfigDump) Render(format string) (string, error) {
+	switch format {
+	case DumpFormatTable, "":
+		return d.String(), nil
+	case DumpFormatJSON:
+		bytes, err := json.MarshalIndent(d, "", "  ")
+
+		return string(bytes), err
+	case DumpFormatYAML:
+		bytes, err := yaml.Marshal(d)
+
+		return string(bytes), err
+	default:
+		return "", errors.Errorf("unsupported dump format %q, supported formats: %s, %s, %s",
+			format, DumpFormatTable, DumpFormatJSON, DumpFormatYAML)
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestDumpNamedFlagSets(t *testing.T) {
+	fs := pflag.NewFlagSet("mysql", pflag.ContinueOnError)
+	fs.String("mysql.host", "127.0.0.1:3306", "")
+	fs.String("mysql.password", "", "")
+
+	if err := MarkSensitive(fs, "mysql.password"); err != nil {
+		t.Fatalf("MarkSensitive has an error: %v\n", err)
+	}
+
+	if err := fs.Parse([]string{"--mysql.password=secret"}); err != nil {
+		t.Fatalf("Parse has an error: %v\n", err)
+	}
+
+	nfs := &NamedFlagSets{FlagSets: map[string]*pflag.FlagSet{}}
+	nfs.AddFlagSet("mysql", fs)
+
+	dump := DumpNamedFlagSets(nfs)
+
+	for _, format := range []string{DumpFormatTable, DumpFormatJSON, DumpFormatYAML} {
+		out, err := dump.Render(format)
+		if err != nil {
+			t.Fatalf("Render(%s) has an error: %v\n", format, err)
+		}
+
+		if strings.Contains(out, "secret") {
+			t.Errorf("Render(%s) leaked a sensitive value: %s\n", format, out)
+		}
+
+		if !strings.Contains(out, "127.0.0.1:3306") {
+			t.Errorf("Render(%s) misses a plain value: %s\n", format, out)
+		}
+	}
+
+	flags := dump.Sections[0].Flags
+	if flags[0].Modified || !flags[1].Modified {
+		t.Errorf("modified mark has an error: got:%v\n", flags)
+	}
+
+	if _, err := dump.Render("xml"); err == nil {
+		t.Errorf("Render should reject unknown formats\n")
+	}
+}
@@ -1,6 +1,12 @@
 package cli
 
-import "github.com/spf13/pflag"
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
 
 type NamedFlagSets struct {
 	Order    []string
@@ -8,6 +14,10 @@ type NamedFlagSets struct {
 }
 
 func (nfs *NamedFlagSets) AddFlagSet(name string, fs *pflag.FlagSet) {
+	if nfs.FlagSets == nil {
+		nfs.FlagSets = make(map[string]*pflag.FlagSet)
+	}
+
 	if _, ok := nfs.FlagSets[name]; !ok {
 		nfs.Order = append(nfs.Order, name)
 		nfs.FlagSets[name] = fs
@@ -16,3 +26,38 @@ func (nfs *NamedFlagSets) AddFlagSet(name string, fs *pflag.FlagSet) {
 
 	nfs.FlagSets[name].AddFlagSet(fs)
 }
+
+// FlagSet returns the flag set with the given name, creating it if needed.
+func (nfs *NamedFlagSets) FlagSet(name string) *pflag.FlagSet {
+	if nfs.FlagSets == nil {
+		nfs.FlagSets = make(map[string]*pflag.FlagSet)
+	}
+
+	if _, ok := nfs.FlagSets[name]; !ok {
+		nfs.Order = append(nfs.Order, name)
+		nfs.FlagSets[name] = pflag.NewFlagSet(name, pflag.ExitOnError)
+	}
+
+	return nfs.FlagSets[name]
+}
+
+// PrintSections prints the usage of every flag set under its own title,
+// wrapped to cols columns when cols is positive.
+func PrintSections(w io.Writer, nfs NamedFlagSets, cols int) {
+	for _, name := range nfs.Order {
+		fs := nfs.FlagSets[name]
+		if !fs.HasAvailableFlags() {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s flags:\n\n%s", title(name), fs.FlagUsagesWrapped(cols))
+	}
+}
+
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
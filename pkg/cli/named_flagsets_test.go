@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintSections(t *testing.T) {
+	nfs := NamedFlagSets{}
+	nfs.FlagSet("").String("config", "", "")
+	nfs.FlagSet("mysql").String("mysql.host", "", "")
+
+	var buf bytes.Buffer
+	PrintSections(&buf, nfs, 0)
+
+	for _, want := range []string{"\n flags:\n\n", "--config", "\nMysql flags:\n\n", "--mysql.host"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("PrintSections has an error: want:%q got:%s\n", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/neee333ko/component-base/pkg/validation"
+	"github.com/neee333ko/errors"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const maxPromptAttempts = 3
+
+var ErrNonInteractive = errors.New("input is not a terminal, refusing to prompt")
+
+// Prompter asks the user for input. The reader and writer are injectable so
+// that prompts can be unit tested.
+type Prompter struct {
+	// AssumeYes answers every confirmation with yes, see AddYesFlag.
+	AssumeYes bool
+	// Interactive is true when the input is a terminal. Confirmations and
+	// selections fail on non-interactive input.
+	Interactive bool
+
+	in     io.Reader
+	out    io.Writer
+	reader *bufio.Reader
+}
+
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	interactive := false
+	if f, ok := in.(*os.File); ok {
+		interactive = term.IsTerminal(int(f.Fd()))
+	}
+
+	return &Prompter{
+		Interactive: interactive,
+		in:          in,
+		out:         out,
+		reader:      bufio.NewReader(in),
+	}
+}
+
+func NewStdPrompter() *Prompter {
+	return NewPrompter(os.Stdin, os.Stderr)
+}
+
+func AddYesFlag(fs *pflag.FlagSet, assumeYes *bool) {
+	fs.BoolVarP(assumeYes, "yes", "y", false, "Automatically answer yes to all prompts and run non-interactively.")
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readSecret reads a line without echo when the input is a terminal.
+func (p *Prompter) readSecret(label string) (string, error) {
+	fmt.Fprint(p.out, label)
+
+	if f, ok := p.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		secret, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(p.out)
+
+		return string(secret), err
+	}
+
+	return p.readLine()
+}
+
+// Password asks for a password which passes validation.IsValidPassword, and
+// asks for it a second time if confirm is true.
+func (p *Prompter) Password(label string, confirm bool) (string, error) {
+	for attempt := 0; attempt < maxPromptAttempts; attempt++ {
+		password, err := p.readSecret(label + ": ")
+		if err != nil {
+			return "", err
+		}
+
+		if errs := validation.IsValidPassword(password); len(errs) != 0 {
+			fmt.Fprintf(p.out, "Invalid password: %s\n", strings.Join(errs, ", "))
+
+			continue
+		}
+
+		if confirm {
+			again, err := p.readSecret("Confirm " + strings.ToLower(label) + ": ")
+			if err != nil {
+				return "", err
+			}
+
+			if again != password {
+				fmt.Fprintln(p.out, "Passwords do not match.")
+
+				continue
+			}
+		}
+
+		return password, nil
+	}
+
+	return "", errors.Errorf("no valid password after %d attempts", maxPromptAttempts)
+}
+
+// Confirm asks a yes/no question, an empty answer selects def.
+func (p *Prompter) Confirm(label string, def bool) (bool, error) {
+	if p.AssumeYes {
+		return true, nil
+	}
+
+	if !p.Interactive {
+		return false, errors.Wrap(ErrNonInteractive, "use --yes to confirm")
+	}
+
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+
+	for attempt := 0; attempt < maxPromptAttempts; attempt++ {
+		fmt.Fprintf(p.out, "%s %s: ", label, hint)
+
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+
+		fmt.Fprintln(p.out, "Please answer yes or no.")
+	}
+
+	return false, errors.Errorf("no valid answer after %d attempts", maxPromptAttempts)
+}
+
+// Select asks the user to pick one of items and returns its index.
+func (p *Prompter) Select(label string, items []string) (int, error) {
+	if len(items) == 0 {
+		return -1, errors.New("nothing to select")
+	}
+
+	if !p.Interactive {
+		return -1, ErrNonInteractive
+	}
+
+	fmt.Fprintf(p.out, "%s\n", label)
+
+	for i, item := range items {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, item)
+	}
+
+	for attempt := 0; attempt < maxPromptAttempts; attempt++ {
+		fmt.Fprintf(p.out, "Enter a number [1-%d]: ", len(items))
+
+		answer, err := p.readLine()
+		if err != nil {
+			return -1, err
+		}
+
+		if n, err := strconv.Atoi(strings.TrimSpace(answer)); err == nil && n >= 1 && n <= len(items) {
+			return n - 1, nil
+		}
+
+		fmt.Fprintf(p.out, "%q is not a valid choice.\n", answer)
+	}
+
+	return -1, errors.Errorf("no valid choice after %d attempts", maxPromptAttempts)
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrompterPassword(t *testing.T) {
+	tests := []struct {
+		input   string
+		confirm bool
+		want    string
+		wantErr bool
+	}{
+		{input: "Admin@2021\n", want: "Admin@2021"},
+		{input: "weak\nAdmin@2021\nAdmin@2021\n", confirm: true, want: "Admin@2021"},
+		{input: "Admin@2021\nAdmin@2022\nAdmin@2021\nAdmin@2021\n", confirm: true, want: "Admin@2021"},
+		{input: "weak\nweak\nweak\n", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p := NewPrompter(strings.NewReader(tt.input), bytes.NewBuffer(nil))
+
+		res, err := p.Password("Password", tt.confirm)
+		if (err != nil) != tt.wantErr || res != tt.want {
+			t.Errorf("Password(%q) has an error: want:%q,%v got:%q,%v\n", tt.input, tt.want, tt.wantErr, res, err)
+		}
+	}
+}
+
+func TestPrompterConfirm(t *testing.T) {
+	tests := []struct {
+		input       string
+		def         bool
+		assumeYes   bool
+		interactive bool
+		want        bool
+		wantErr     bool
+	}{
+		{input: "y\n", interactive: true, want: true},
+		{input: "\n", def: true, interactive: true, want: true},
+		{input: "maybe\nno\n", def: true, interactive: true, want: false},
+		{input: "", assumeYes: true, want: true},
+		{input: "y\n", interactive: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p := NewPrompter(strings.NewReader(tt.input), bytes.NewBuffer(nil))
+		p.AssumeYes = tt.assumeYes
+		p.Interactive = tt.interactive
+
+		res, err := p.Confirm("Delete user?", tt.def)
+		if (err != nil) != tt.wantErr || res != tt.want {
+			t.Errorf("Confirm(%q) has an error: want:%v,%v got:%v,%v\n", tt.input, tt.want, tt.wantErr, res, err)
+		}
+	}
+}
+
+func TestPrompterSelect(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	p := NewPrompter(strings.NewReader("0\n2\n"), out)
+	p.Interactive = true
+
+	res, err := p.Select("Choose a secret:", []string{"secret-a", "secret-b"})
+	if err != nil || res != 1 {
+		t.Errorf("Select has an error: want:1 got:%d,%v\n", res, err)
+	}
+
+	if !strings.Contains(out.String(), "  2) secret-b") {
+		t.Errorf("Select did not print the items: %s\n", out.String())
+	}
+}
@@ -0,0 +1,440 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultWatchInterval = 5 * time.Second
+
+// Validatable is implemented by options structs, reloads are rejected when
+// Validate returns errors.
+type Validatable interface {
+	Validate() []error
+}
+
+// ChangeFunc is called with the old and new value of a watched field or
+// section after a reload has been applied.
+type ChangeFunc func(oldValue, newValue interface{})
+
+type changeCallback struct {
+	path string
+	fn   ChangeFunc
+}
+
+// ConfigWatcher polls a config file and re-parses it into an options struct.
+type ConfigWatcher struct {
+	path     string
+	interval time.Duration
+	opts     interface{}
+
+	mu        sync.RWMutex
+	callbacks []changeCallback
+	modTime   time.Time
+	size      int64
+	sum       [sha256.Size]byte
+}
+
+// NewConfigWatcher watches the file at path, opts must be a pointer to the
+// struct the file was loaded into. JSON and YAML files are supported, fields
+// are matched by their json tags. The current file counts as loaded, so
+// values overridden by flags are kept until the file is edited.
+func NewConfigWatcher(path string, opts interface{}, interval time.Duration) (*ConfigWatcher, error) {
+	v := reflect.ValueOf(opts)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("options must be a non-nil pointer to struct")
+	}
+
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		path:     path,
+		interval: interval,
+		opts:     opts,
+	}
+	w.markLoaded(info, sha256.Sum256(data))
+
+	return w, nil
+}
+
+// OnChange registers fn for a dot separated path of json names, e.g.
+// "log.level" for a field or "log" for a whole section.
+func (w *ConfigWatcher) OnChange(path string, fn ChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callbacks = append(w.callbacks, changeCallback{path: path, fn: fn})
+}
+
+// RLock must be held by readers of the options struct running concurrently
+// with the watcher.
+func (w *ConfigWatcher) RLock()   { w.mu.RLock() }
+func (w *ConfigWatcher) RUnlock() { w.mu.RUnlock() }
+
+func (w *ConfigWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Reload(); err != nil {
+				log.Warnf("reload config file %s failed: %s\n", w.path, err.Error())
+			}
+		}
+	}
+}
+
+// Reload re-parses the config file if it changed since the last reload.
+// The new options are applied only if they pass validation.
+func (w *ConfigWatcher) Reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.RLock()
+	unchanged := info.ModTime().Equal(w.modTime) && info.Size() == w.size
+	w.mu.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+
+	w.mu.RLock()
+	sameContent := sum == w.sum
+	w.mu.RUnlock()
+
+	if sameContent {
+		w.markLoaded(info, sum)
+
+		return nil
+	}
+
+	w.mu.RLock()
+	newOpts, err := w.decode(data)
+	w.mu.RUnlock()
+
+	if err != nil {
+		// do not parse the same content again on every tick
+		w.markLoaded(info, sum)
+
+		return errors.Wrapf(err, "parse config file %s", w.path)
+	}
+
+	if v, ok := newOpts.Interface().(Validatable); ok {
+		if agg := errors.NewAggregate(v.Validate()); agg != nil {
+			w.markLoaded(info, sum)
+
+			return errors.Wrap(agg, "reject invalid config")
+		}
+	}
+
+	type change struct {
+		fn       ChangeFunc
+		old, new interface{}
+	}
+
+	w.mu.Lock()
+
+	changes := make([]change, 0)
+	current := reflect.ValueOf(w.opts)
+
+	for _, cb := range w.callbacks {
+		oldValue, _ := lookupPath(current, cb.path)
+		newValue, _ := lookupPath(newOpts, cb.path)
+
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, change{fn: cb.fn, old: oldValue, new: newValue})
+		}
+	}
+
+	current.Elem().Set(newOpts.Elem())
+	w.modTime, w.size, w.sum = info.ModTime(), info.Size(), sum
+	w.mu.Unlock()
+
+	for _, c := range changes {
+		c.fn(c.old, c.new)
+	}
+
+	return nil
+}
+
+func (w *ConfigWatcher) markLoaded(info os.FileInfo, sum [sha256.Size]byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.modTime, w.size, w.sum = info.ModTime(), info.Size(), sum
+}
+
+// decode applies data on top of a deep copy of the current options so that
+// values missing from the file, and fields the file cannot set, keep their
+// values. Durations may be written as strings, e.g. "5s".
+func (w *ConfigWatcher) decode(data []byte) (reflect.Value, error) {
+	var m interface{}
+
+	switch strings.ToLower(filepath.Ext(w.path)) {
+	case ".yaml", ".yml":
+		if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&m); err != nil {
+			return reflect.Value{}, err
+		}
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+
+		if err := d.Decode(&m); err != nil {
+			return reflect.Value{}, err
+		}
+	default:
+		return reflect.Value{}, errors.Errorf("unsupported config file type %q", filepath.Ext(w.path))
+	}
+
+	t := reflect.TypeOf(w.opts)
+
+	m, err := parseDurations(m, t, "")
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if data, err = json.Marshal(m); err != nil {
+		return reflect.Value{}, err
+	}
+
+	newOpts := deepCopy(reflect.ValueOf(w.opts))
+	if err := json.Unmarshal(data, newOpts.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return newOpts, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseDurations replaces the duration strings of v, decoded for type t, by
+// nanoseconds.
+func parseDurations(v interface{}, t reflect.Type, path string) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		s, ok := v.(string)
+		if !ok {
+			return v, nil
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid duration %s", path)
+		}
+
+		return int64(d), nil
+	}
+
+	var err error
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+
+		for k, val := range m {
+			if index, ok := jsonFieldIndex(t, k); ok {
+				if m[k], err = parseDurations(val, t.FieldByIndex(index).Type, join(path, k)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+
+		for k, val := range m {
+			if m[k], err = parseDurations(val, t.Elem(), join(path, k)); err != nil {
+				return nil, err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		s, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+
+		for i, val := range s {
+			if s[i], err = parseDurations(val, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return v, nil
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// deepCopy copies v including its unexported fields, pointers, maps and
+// slices reachable through exported fields are copied too.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		cp := reflect.New(v.Type().Elem())
+		cp.Elem().Set(deepCopy(v.Elem()))
+
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				cp.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			cp.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i)))
+		}
+
+		return cp
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(deepCopy(v.Elem()))
+
+		return cp
+	}
+
+	return v
+}
+
+func lookupPath(v reflect.Value, path string) (interface{}, bool) {
+	if path == "" {
+		return v.Elem().Interface(), true
+	}
+
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, false
+			}
+
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			return nil, false
+		}
+
+		var ok bool
+		if v, ok = fieldByJSONName(v, name); !ok {
+			return nil, false
+		}
+	}
+
+	return v.Interface(), true
+}
+
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	index, ok := jsonFieldIndex(v.Type(), name)
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	return v.FieldByIndex(index), true
+}
+
+// jsonFieldIndex returns the index of the field of t named name in JSON,
+// embedded structs are searched too.
+func jsonFieldIndex(t reflect.Type, name string) ([]int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := strings.Split(sf.Tag.Get("json"), ",")[0]
+
+		if tag == "" && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if index, ok := jsonFieldIndex(sf.Type, name); ok {
+				return append([]int{i}, index...), true
+			}
+
+			continue
+		}
+
+		if tag == name || (tag == "" && strings.EqualFold(sf.Name, name)) {
+			return []int{i}, true
+		}
+	}
+
+	return nil, false
+}
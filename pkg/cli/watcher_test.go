@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testLogOptions struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type testOptions struct {
+	Log       *testLogOptions `json:"log"`
+	RateLimit int             `json:"rate-limit"`
+	Period    time.Duration   `json:"period"`
+	Secret    string          `json:"-"`
+	loaded    bool
+}
+
+func (o *testOptions) Validate() []error {
+	if o.RateLimit < 0 {
+		return []error{fmt.Errorf("rate-limit must not be negative")}
+	}
+
+	return nil
+}
+
+func TestConfigWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	opts := &testOptions{Log: &testLogOptions{Level: "info", Format: "console"}, RateLimit: 10, Secret: "keep", loaded: true}
+
+	if err := os.WriteFile(path, []byte("log:\n  level: info\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewConfigWatcher(path, opts, time.Second)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher has an error: %v\n", err)
+	}
+
+	var levelChanges, logChanges, limitChanges int
+	w.OnChange("log.level", func(oldValue, newValue interface{}) {
+		levelChanges++
+
+		if oldValue != "info" || newValue != "debug" {
+			t.Errorf("log.level change has an error: got:%v -> %v\n", oldValue, newValue)
+		}
+	})
+	w.OnChange("log", func(oldValue, newValue interface{}) { logChanges++ })
+	w.OnChange("rate-limit", func(oldValue, newValue interface{}) { limitChanges++ })
+
+	tests := []struct {
+		content string
+		wantErr bool
+		want    testOptions
+	}{
+		{
+			content: "log:\n  level: debug\n",
+			want:    testOptions{Log: &testLogOptions{Level: "debug", Format: "console"}, RateLimit: 10},
+		},
+		{
+			content: "log:\n  level: debug\nrate-limit: -1\n",
+			wantErr: true,
+			want:    testOptions{Log: &testLogOptions{Level: "debug", Format: "console"}, RateLimit: 10},
+		},
+		{
+			content: "log:\n  level: debug\nperiod: 5s\n",
+			want:    testOptions{Log: &testLogOptions{Level: "debug", Format: "console"}, RateLimit: 10, Period: 5 * time.Second},
+		},
+		{
+			content: "log:\n  level: debug\nperiod: five\n",
+			wantErr: true,
+			want:    testOptions{Log: &testLogOptions{Level: "debug", Format: "console"}, RateLimit: 10, Period: 5 * time.Second},
+		},
+	}
+
+	for i, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		// make sure the modification time differs from the previous write
+		_ = os.Chtimes(path, time.Now(), time.Now().Add(time.Duration(i+1)*time.Second))
+
+		if err := w.Reload(); (err != nil) != tt.wantErr {
+			t.Errorf("Reload has an error: want error:%v got:%v\n", tt.wantErr, err)
+		}
+
+		if opts.RateLimit != tt.want.RateLimit || opts.Period != tt.want.Period || *opts.Log != *tt.want.Log {
+			t.Errorf("Reload has an error: want:%+v got:%+v\n", tt.want, opts)
+		}
+
+		if opts.Secret != "keep" || !opts.loaded {
+			t.Errorf("Reload has an error: fields not set by the file were lost: %+v\n", opts)
+		}
+
+		// rejected content is reported only once
+		if err := w.Reload(); err != nil {
+			t.Errorf("Reload of unchanged file has an error: %v\n", err)
+		}
+	}
+
+	if levelChanges != 1 || logChanges != 1 || limitChanges != 0 {
+		t.Errorf("callbacks have an error: got level:%d log:%d rate-limit:%d\n", levelChanges, logChanges, limitChanges)
+	}
+}
+
+func TestConfigWatcherUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("rate-limit: 10\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// rate-limit was overridden by a flag
+	opts := &testOptions{Log: &testLogOptions{Level: "info"}, RateLimit: 20}
+
+	w, err := NewConfigWatcher(path, opts, time.Second)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher has an error: %v\n", err)
+	}
+
+	changes := 0
+	w.OnChange("rate-limit", func(oldValue, newValue interface{}) { changes++ })
+
+	if err := w.Reload(); err != nil || opts.RateLimit != 20 || changes != 0 {
+		t.Errorf("Reload of unchanged file has an error: want:20,0 got:%d,%d,%v\n", opts.RateLimit, changes, err)
+	}
+}
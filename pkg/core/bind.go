@@ -0,0 +1,65 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/errors"
+)
+
+// Defaulter is implemented by request objects that fill in default values
+// once they are bound.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// ShouldBindAndValidate binds the request body, the query (form tags) and the
+// URI params (uri tags) into obj, in that order so that path params win,
+// applies the defaults of a Defaulter and validates obj with the project
+// validator. Errors are coded with ErrBind, ErrUnsupportedMediaType or
+// ErrValidation.
+func ShouldBindAndValidate(c *gin.Context, obj interface{}) error {
+	if hasBody(c.Request) {
+		if err := Decode(c, obj); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Request.URL.Query()) > 0 {
+		if err := c.ShouldBindQuery(obj); err != nil {
+			return errors.WrapC(err, ErrBind, err.Error())
+		}
+	}
+
+	if len(c.Params) > 0 {
+		if err := c.ShouldBindUri(obj); err != nil {
+			return errors.WrapC(err, ErrBind, err.Error())
+		}
+	}
+
+	if d, ok := obj.(Defaulter); ok {
+		d.SetDefaults()
+	}
+
+	if errs := Validate(c, obj); len(errs) != 0 {
+		return errors.WrapC(errs.ToAggregate(), ErrValidation, "validation failed")
+	}
+
+	return nil
+}
+
+// BindAndValidate is ShouldBindAndValidate writing the error response, it
+// returns false when the handler must stop.
+func BindAndValidate(c *gin.Context, obj interface{}) bool {
+	if err := ShouldBindAndValidate(c, obj); err != nil {
+		WriteResponse(c, err, nil)
+
+		return false
+	}
+
+	return true
+}
+
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
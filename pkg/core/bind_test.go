@@ -0,0 +1,75 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+)
+
+type testCreateRequest struct {
+	Name        string `json:"name" uri:"name" validate:"name"`
+	Description string `json:"description" validate:"description"`
+	Limit       int64  `json:"limit" form:"limit"`
+}
+
+func (r *testCreateRequest) SetDefaults() {
+	if r.Limit == 0 {
+		r.Limit = 10
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	tests := []struct {
+		target     string
+		body       string
+		name       string
+		wantStatus int
+		want       testCreateRequest
+	}{
+		{
+			target: "/v1/users/colin", body: `{"name":"ignored","description":"admin"}`, name: "colin",
+			wantStatus: http.StatusOK, want: testCreateRequest{Name: "colin", Description: "admin", Limit: 10},
+		},
+		{
+			target: "/v1/users/colin?limit=5", name: "colin",
+			wantStatus: http.StatusOK, want: testCreateRequest{Name: "colin", Limit: 5},
+		},
+		{target: "/v1/users/colin?limit=five", name: "colin", wantStatus: http.StatusBadRequest},
+		{target: "/v1/users/colin", body: `{"name":`, name: "colin", wantStatus: http.StatusBadRequest},
+		{target: "/v1/users/-colin", name: "-colin", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+		c.Params = gin.Params{{Key: "name", Value: tt.name}}
+
+		var req testCreateRequest
+		if BindAndValidate(c, &req) {
+			c.Status(http.StatusOK)
+		}
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("BindAndValidate(%s) has an error: want:%d got:%d,%s\n", tt.target, tt.wantStatus, w.Code, w.Body.String())
+
+			continue
+		}
+
+		if tt.wantStatus == http.StatusOK && req != tt.want {
+			t.Errorf("BindAndValidate(%s) has an error: want:%+v got:%+v\n", tt.target, tt.want, req)
+		}
+
+		if tt.wantStatus == http.StatusUnprocessableEntity {
+			var resp Response
+			want := ErrorDetail{Field: "name", Type: "Invalid", BadValue: tt.name, Detail: "is not a valid name"}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Details) != 1 || resp.Details[0] != want {
+				t.Errorf("BindAndValidate(%s) details has an error: want:%+v got:%s\n", tt.target, want, w.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+// Codes of the errors written by pkg/core, the 9900xx range is reserved for
+// component-base so that it does not clash with the codes of the services
+// built on it.
+const (
+	// ErrBind - 400: Error occurred while binding the request body to the struct.
+	ErrBind int = iota + 990001
+
+	// ErrValidation - 422: Validation failed.
+	ErrValidation
+
+	// ErrNotAcceptable - 406: None of the accepted media types is supported.
+	ErrNotAcceptable
+
+	// ErrUnsupportedMediaType - 415: The content type of the request body is not supported.
+	ErrUnsupportedMediaType
+
+	// ErrEncode - 500: Error occurred while encoding the response.
+	ErrEncode
+
+	// ErrPreconditionFailed - 412: The object has been modified since it was read.
+	ErrPreconditionFailed
+
+	// ErrInternal - 500: Internal server error.
+	ErrInternal
+
+	// ErrPatch - 422: The patch could not be applied.
+	ErrPatch
+
+	// ErrIdempotencyKeyInUse - 409: A request with the same idempotency key is being processed.
+	ErrIdempotencyKeyInUse
+
+	// ErrIdempotencyKeyMismatch - 422: The idempotency key was used for a different request.
+	ErrIdempotencyKeyMismatch
+
+	// ErrTooManyRequests - 429: Too many requests.
+	ErrTooManyRequests
+)
+
+type coder struct {
+	code      int
+	status    int
+	message   string
+	reference string
+}
+
+var _ errors.Coder = &coder{}
+
+func (c *coder) Code() int         { return c.code }
+func (c *coder) HttpStatus() int   { return c.status }
+func (c *coder) Message() string   { return c.message }
+func (c *coder) Reference() string { return c.reference }
+
+// register keeps the coder of an application that already registered code.
+func register(code int, status int, message string) {
+	if err := errors.Register(&coder{code: code, status: status, message: message}); err != nil {
+		log.Warnf("error code %d is already registered, keep the registered coder\n", code)
+	}
+}
+
+func init() {
+	register(ErrBind, http.StatusBadRequest, "Error occurred while binding the request body to the struct")
+	register(ErrValidation, http.StatusUnprocessableEntity, "Validation failed")
+	register(ErrNotAcceptable, http.StatusNotAcceptable, "None of the accepted media types is supported")
+	register(ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "The content type of the request body is not supported")
+	register(ErrEncode, http.StatusInternalServerError, "Error occurred while encoding the response")
+	register(ErrPreconditionFailed, http.StatusPreconditionFailed, "The object has been modified since it was read")
+	register(ErrInternal, http.StatusInternalServerError, "Internal server error")
+	register(ErrPatch, http.StatusUnprocessableEntity, "The patch could not be applied")
+	register(ErrIdempotencyKeyInUse, http.StatusConflict, "A request with the same idempotency key is being processed")
+	register(ErrIdempotencyKeyMismatch, http.StatusUnprocessableEntity, "The idempotency key was used for a different request")
+	register(ErrTooManyRequests, http.StatusTooManyRequests, "Too many requests")
+}
@@ -0,0 +1,27 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/neee333ko/errors"
+)
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		code       int
+		wantStatus int
+	}{
+		{code: ErrBind, wantStatus: http.StatusBadRequest},
+		{code: ErrTooManyRequests, wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		// registering a taken code keeps the registered coder
+		register(tt.code, http.StatusTeapot, "taken")
+
+		if got := errors.ParseCoder(errors.WithCode(tt.code, "")).HttpStatus(); got != tt.wantStatus {
+			t.Errorf("register(%d) has an error: want:%d got:%d\n", tt.code, tt.wantStatus, got)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/errors"
+)
+
+// Versioner is implemented by objects carrying a version, e.g. an optimistic
+// locking column, which is then hashed for the ETag instead of the object.
+type Versioner interface {
+	GetResourceVersion() string
+}
+
+// ETag returns the strong entity tag of obj encoded as contentType, quoted as
+// sent in headers. Every representation of obj has its own tag.
+func ETag(obj interface{}, contentType string) (string, error) {
+	var data []byte
+
+	if v, ok := obj.(Versioner); ok && v.GetResourceVersion() != "" {
+		data = []byte(v.GetResourceVersion())
+	} else {
+		var err error
+		if data, err = json.Marshal(obj); err != nil {
+			return "", err
+		}
+	}
+
+	return etag(contentType, data), nil
+}
+
+func etag(contentType string, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(contentType))
+	h.Write([]byte{0})
+	h.Write(data)
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// lastModified returns the UpdatedAt of obj truncated to the precision of
+// HTTP dates.
+func lastModified(obj interface{}) time.Time {
+	accessor, ok := obj.(metav1.ObjectAccessor)
+	if !ok {
+		return time.Time{}
+	}
+
+	return accessor.GetObject().GetUpdatedAt().UTC().Truncate(time.Second)
+}
+
+// WriteConditionalResponse sets the ETag and Last-Modified headers of obj
+// and answers 304 Not Modified when If-None-Match or If-Modified-Since show
+// that the client copy is current, otherwise obj is written by
+// WriteResponse.
+func WriteConditionalResponse(c *gin.Context, obj interface{}) {
+	s, err := Negotiate(c)
+	if err != nil {
+		WriteResponse(c, nil, obj)
+
+		return
+	}
+
+	etag, err := ETag(obj, s.ContentType())
+	if err != nil {
+		WriteResponse(c, errors.WrapC(err, ErrEncode, err.Error()), nil)
+
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Vary", "Accept")
+
+	modified := lastModified(obj)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, modified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+
+		return
+	}
+
+	WriteResponse(c, nil, obj)
+}
+
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	// If-Modified-Since is ignored when If-None-Match is present, RFC 9110 13.1.3.
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return matchETag(inm, etag, true)
+	}
+
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+
+	return !modified.After(since)
+}
+
+// CheckPreconditions compares the If-Match and If-Unmodified-Since headers of
+// an update or delete with the current object, the error is coded with
+// ErrPreconditionFailed. If-Match may carry the tag of any representation of
+// the object.
+func CheckPreconditions(c *gin.Context, current interface{}) error {
+	if im := c.GetHeader("If-Match"); im != "" {
+		for _, contentType := range contentTypes() {
+			etag, err := ETag(current, contentType)
+			if err != nil {
+				return errors.WrapC(err, ErrEncode, err.Error())
+			}
+
+			if matchETag(im, etag, false) {
+				return nil
+			}
+		}
+
+		return errors.WithCode(ErrPreconditionFailed, fmt.Sprintf("If-Match %s does not match the object", im))
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Unmodified-Since"))
+	if err != nil {
+		return nil
+	}
+
+	if modified := lastModified(current); modified.After(since) {
+		return errors.WithCode(ErrPreconditionFailed, "object was modified at "+modified.Format(http.TimeFormat))
+	}
+
+	return nil
+}
+
+// matchETag reports whether etag is listed in header, weak tags only match
+// with the weak comparison of If-None-Match.
+func matchETag(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
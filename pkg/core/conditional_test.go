@@ -0,0 +1,87 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/errors"
+)
+
+type testObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+}
+
+func TestWriteConditionalResponse(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	obj := &testObject{ObjectMeta: metav1.ObjectMeta{Name: "colin", UpdatedAt: updated}}
+	etag, _ := ETag(obj, jsonSerializer{}.ContentType())
+	yamlETag, _ := ETag(obj, yamlSerializer{}.ContentType())
+
+	tests := []struct {
+		method string
+		header map[string]string
+		want   int
+		etag   string
+	}{
+		{method: http.MethodGet, want: http.StatusOK},
+		{method: http.MethodGet, header: map[string]string{"If-None-Match": etag}, want: http.StatusNotModified},
+		{method: http.MethodGet, header: map[string]string{"If-None-Match": `"other", W/` + etag}, want: http.StatusNotModified},
+		{method: http.MethodGet, header: map[string]string{"If-None-Match": `"other"`}, want: http.StatusOK},
+		{
+			method: http.MethodGet,
+			header: map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": updated.Format(http.TimeFormat)},
+			want:   http.StatusOK,
+		},
+		{method: http.MethodGet, header: map[string]string{"If-Modified-Since": updated.Format(http.TimeFormat)}, want: http.StatusNotModified},
+		{method: http.MethodGet, header: map[string]string{"If-Modified-Since": updated.Add(-time.Hour).Format(http.TimeFormat)}, want: http.StatusOK},
+		{method: http.MethodPost, header: map[string]string{"If-None-Match": etag}, want: http.StatusOK},
+		{method: http.MethodGet, header: map[string]string{"Accept": "application/yaml", "If-None-Match": etag}, want: http.StatusOK, etag: yamlETag},
+		{method: http.MethodGet, header: map[string]string{"Accept": "application/yaml", "If-None-Match": yamlETag}, want: http.StatusNotModified, etag: yamlETag},
+	}
+
+	for _, tt := range tests {
+		if tt.etag == "" {
+			tt.etag = etag
+		}
+
+		c, w := newTestContext(tt.method, "/v1/users/colin", tt.header)
+		WriteConditionalResponse(c, obj)
+
+		if w.Code != tt.want || w.Header().Get("ETag") != tt.etag || w.Header().Get("Vary") != "Accept" ||
+			w.Header().Get("Last-Modified") != updated.Format(http.TimeFormat) {
+			t.Errorf("WriteConditionalResponse(%v) has an error: want:%d got:%d,%v\n", tt.header, tt.want, w.Code, w.Header())
+		}
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	obj := &testObject{ObjectMeta: metav1.ObjectMeta{Name: "colin", UpdatedAt: updated}}
+	etag, _ := ETag(obj, jsonSerializer{}.ContentType())
+	yamlETag, _ := ETag(obj, yamlSerializer{}.ContentType())
+
+	tests := []struct {
+		header  map[string]string
+		wantErr bool
+	}{
+		{header: nil},
+		{header: map[string]string{"If-Match": etag}},
+		{header: map[string]string{"If-Match": yamlETag}},
+		{header: map[string]string{"If-Match": "*"}},
+		{header: map[string]string{"If-Match": `"stale"`}, wantErr: true},
+		{header: map[string]string{"If-Match": "W/" + etag}, wantErr: true},
+		{header: map[string]string{"If-Unmodified-Since": updated.Format(http.TimeFormat)}},
+		{header: map[string]string{"If-Unmodified-Since": updated.Add(-time.Hour).Format(http.TimeFormat)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		c, _ := newTestContext(http.MethodPut, "/v1/users/colin", tt.header)
+
+		err := CheckPreconditions(c, obj)
+		if (err != nil) != tt.wantErr || (err != nil && errors.ParseCoder(err).HttpStatus() != http.StatusPreconditionFailed) {
+			t.Errorf("CheckPreconditions(%v) has an error: want:%v got:%v\n", tt.header, tt.wantErr, err)
+		}
+	}
+}
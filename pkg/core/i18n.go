@@ -0,0 +1,186 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/validation"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+	"github.com/neee333ko/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog holds the translated messages of coded errors per locale.
+type Catalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[int]string
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{messages: make(map[string]map[int]string)}
+}
+
+var (
+	catalogMu      sync.RWMutex
+	defaultCatalog = NewCatalog()
+)
+
+// SetCatalog replaces the catalog used by WriteResponse.
+func SetCatalog(c *Catalog) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	defaultCatalog = c
+}
+
+func GetCatalog() *Catalog {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	return defaultCatalog
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func (c *Catalog) Add(locale string, messages map[int]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = make(map[int]string, len(messages))
+	}
+
+	for code, message := range messages {
+		c.messages[locale][code] = message
+	}
+}
+
+// LoadFile loads a YAML or JSON file mapping codes to messages. The locale is
+// taken from the file name, e.g. zh.yaml or messages.zh-CN.json.
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	locale := name[strings.LastIndex(name, ".")+1:]
+
+	raw := make(map[string]string)
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return errors.Wrapf(err, "parse message catalog %s", path)
+	}
+
+	messages := make(map[int]string, len(raw))
+
+	for k, v := range raw {
+		code, err := strconv.Atoi(k)
+		if err != nil {
+			return errors.Errorf("invalid code %q in message catalog %s", k, path)
+		}
+
+		messages[code] = v
+	}
+
+	c.Add(locale, messages)
+
+	return nil
+}
+
+// LoadDir loads every .yaml, .yml and .json file of dir.
+func (c *Catalog) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if err := c.LoadFile(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Message returns the message of code in the first locale that has one,
+// trying the base language of regional locales too, or def.
+func (c *Catalog) Message(locales []string, code int, def string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+
+		if message, ok := c.messages[locale][code]; ok {
+			return message
+		}
+
+		if i := strings.Index(locale, "-"); i > 0 {
+			if message, ok := c.messages[locale[:i]][code]; ok {
+				return message
+			}
+		}
+	}
+
+	return def
+}
+
+// Locales returns the locales of the Accept-Language header ordered by
+// preference.
+func Locales(c *gin.Context) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	ranges := make([]weighted, 0)
+
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		if q > 0 {
+			ranges = append(ranges, weighted{locale: normalizeLocale(tag), q: q})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	locales := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		locales = append(locales, r.locale)
+	}
+
+	return locales
+}
+
+// Validate validates obj with the project validator, translating the
+// messages into the language requested by the client.
+func Validate(c *gin.Context, obj interface{}) field.ErrorList {
+	return validation.NewValidator(obj).WithLocale(Locales(c)...).Validate()
+}
+
+func localizedMessage(c *gin.Context, coder errors.Coder) string {
+	return GetCatalog().Message(Locales(c), coder.Code(), coder.Message())
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+)
+
+func TestLocales(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{header: "", want: []string{}},
+		{header: "zh-CN,zh;q=0.9,en;q=0.8", want: []string{"zh-cn", "zh", "en"}},
+		{header: "en;q=0.5, zh_TW, *;q=0.1, fr;q=0", want: []string{"zh-tw", "en"}},
+	}
+
+	for _, tt := range tests {
+		c, _ := newTestContext(http.MethodGet, "/", map[string]string{"Accept-Language": tt.header})
+
+		if got := Locales(c); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Locales(%s) has an error: want:%v got:%v\n", tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "zh.yaml"), []byte("\"990002\": 请求参数校验失败\n"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "messages.zh-TW.json"), []byte(`{"990002": "請求參數校驗失敗"}`), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600)
+
+	catalog := NewCatalog()
+	if err := catalog.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir has an error: %v\n", err)
+	}
+
+	tests := []struct {
+		locales []string
+		want    string
+	}{
+		{locales: nil, want: "default"},
+		{locales: []string{"zh-cn"}, want: "请求参数校验失败"},
+		{locales: []string{"zh-TW"}, want: "請求參數校驗失敗"},
+		{locales: []string{"fr", "zh"}, want: "请求参数校验失败"},
+	}
+
+	for _, tt := range tests {
+		if got := catalog.Message(tt.locales, ErrValidation, "default"); got != tt.want {
+			t.Errorf("Message(%v) has an error: want:%s got:%s\n", tt.locales, tt.want, got)
+		}
+	}
+
+	SetCatalog(catalog)
+	defer SetCatalog(NewCatalog())
+
+	c, w := newTestContext(http.MethodGet, "/", map[string]string{"Accept-Language": "zh-CN"})
+	WriteResponse(c, errors.WithCode(ErrValidation, "name is invalid"), nil)
+
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Message != "请求参数校验失败" {
+		t.Errorf("localized WriteResponse has an error: %s\n", w.Body.String())
+	}
+}
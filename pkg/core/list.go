@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+)
+
+// DefaultMaxListLimit is the largest page size when none is configured.
+const DefaultMaxListLimit int64 = 1000
+
+// ListResponse is the payload of a paginated list.
+type ListResponse struct {
+	TotalCount int64       `json:"totalCount"`
+	Limit      int64       `json:"limit"`
+	Offset     int64       `json:"offset"`
+	Items      interface{} `json:"items"`
+}
+
+// ValidateListOptions rejects negative limit and offset, a missing limit or a
+// limit above maxLimit is clamped to maxLimit.
+func ValidateListOptions(opts *metav1.ListOptions, maxLimit int64) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if maxLimit <= 0 {
+		maxLimit = DefaultMaxListLimit
+	}
+
+	if opts.Limit < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("limit"), opts.Limit, "must be greater than or equal to 0"))
+	}
+
+	if opts.Offset < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("offset"), opts.Offset, "must be greater than or equal to 0"))
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+
+	if opts.Limit == 0 || opts.Limit > maxLimit {
+		opts.Limit = maxLimit
+	}
+
+	return nil
+}
+
+// WriteListResponse writes one page of items with RFC 8288 Link headers
+// pointing to the first, previous, next and last pages, or a metav1.Table of
+// the items, converted with tableOpts, when requested with
+// `Accept: application/json;as=Table`. opts must have been checked by
+// ValidateListOptions, tableOpts may be nil.
+func WriteListResponse(c *gin.Context, opts *metav1.ListOptions, tableOpts *metav1.TableOptions, totalCount int64, items interface{}) {
+	if links := paginationLinks(c.Request, opts.Limit, opts.Offset, totalCount); links != "" {
+		c.Header("Link", links)
+	}
+
+	if wantsTable(c) {
+		table := metav1.ConvertToTable(items, tableOpts)
+		table.TotalCount = totalCount
+
+		WriteResponse(c, nil, table)
+
+		return
+	}
+
+	WriteResponse(c, nil, &ListResponse{
+		TotalCount: totalCount,
+		Limit:      opts.Limit,
+		Offset:     opts.Offset,
+		Items:      items,
+	})
+}
+
+func wantsTable(c *gin.Context) bool {
+	for _, r := range parseAccept(c.GetHeader("Accept")) {
+		if r.mediaType == "application/json" && r.params["as"] == "Table" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func paginationLinks(req *http.Request, limit, offset, total int64) string {
+	if limit <= 0 {
+		return ""
+	}
+
+	last := int64(0)
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+
+	links := make([]string, 0, 4)
+	link := func(rel string, offset int64) {
+		links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", pageURL(req, limit, offset), rel))
+	}
+
+	link("first", 0)
+
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+
+		link("prev", prev)
+	}
+
+	if offset+limit < total {
+		link("next", offset+limit)
+	}
+
+	link("last", last)
+
+	return strings.Join(links, ", ")
+}
+
+func pageURL(req *http.Request, limit, offset int64) string {
+	query := req.URL.Query()
+	query.Set("limit", strconv.FormatInt(limit, 10))
+	query.Set("offset", strconv.FormatInt(offset, 10))
+
+	u := &url.URL{Path: req.URL.Path, RawPath: req.URL.RawPath, RawQuery: query.Encode()}
+
+	if req.Host != "" {
+		u.Host = req.Host
+		u.Scheme = "http"
+
+		if req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https" {
+			u.Scheme = "https"
+		}
+	}
+
+	return u.String()
+}
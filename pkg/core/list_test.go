@@ -0,0 +1,123 @@
+package core
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+)
+
+func TestValidateListOptions(t *testing.T) {
+	tests := []struct {
+		opts      metav1.ListOptions
+		wantLimit int64
+		wantErrs  int
+	}{
+		{opts: metav1.ListOptions{Limit: 10}, wantLimit: 10},
+		{opts: metav1.ListOptions{}, wantLimit: 100},
+		{opts: metav1.ListOptions{Limit: 500}, wantLimit: 100},
+		{opts: metav1.ListOptions{Limit: -1, Offset: -1}, wantLimit: -1, wantErrs: 2},
+	}
+
+	for _, tt := range tests {
+		errs := ValidateListOptions(&tt.opts, 100)
+
+		if len(errs) != tt.wantErrs || tt.opts.Limit != tt.wantLimit {
+			t.Errorf("ValidateListOptions has an error: want:%d,%d got:%d,%v\n", tt.wantLimit, tt.wantErrs, tt.opts.Limit, errs)
+		}
+	}
+}
+
+func TestWriteListResponse(t *testing.T) {
+	tests := []struct {
+		target    string
+		total     int64
+		wantLinks []string
+	}{
+		{
+			target: "/v1/users?limit=10&offset=20&fieldSelector=name%3Dcolin",
+			total:  45,
+			wantLinks: []string{
+				`<http://example.com/v1/users?fieldSelector=name%3Dcolin&limit=10&offset=0>; rel="first"`,
+				`<http://example.com/v1/users?fieldSelector=name%3Dcolin&limit=10&offset=10>; rel="prev"`,
+				`<http://example.com/v1/users?fieldSelector=name%3Dcolin&limit=10&offset=30>; rel="next"`,
+				`<http://example.com/v1/users?fieldSelector=name%3Dcolin&limit=10&offset=40>; rel="last"`,
+			},
+		},
+		{
+			target: "/v1/users?limit=10",
+			total:  0,
+			wantLinks: []string{
+				`<http://example.com/v1/users?limit=10&offset=0>; rel="first"`,
+				`<http://example.com/v1/users?limit=10&offset=0>; rel="last"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		c, w := newTestContext(http.MethodGet, tt.target, nil)
+
+		var opts metav1.ListOptions
+		if err := c.ShouldBindQuery(&opts); err != nil {
+			t.Fatalf("bind query has an error: %v\n", err)
+		}
+
+		ValidateListOptions(&opts, 100)
+		WriteListResponse(c, &opts, nil, tt.total, []string{})
+
+		if links := w.Header().Get("Link"); links != strings.Join(tt.wantLinks, ", ") {
+			t.Errorf("Link header has an error:\nwant:%s\ngot: %s\n", strings.Join(tt.wantLinks, ", "), links)
+		}
+
+		var resp ListResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.TotalCount != tt.total || resp.Limit != opts.Limit {
+			t.Errorf("list body has an error: %s\n", w.Body.String())
+		}
+	}
+}
+
+func TestWriteListResponseTable(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour)
+	items := []testObject{
+		{ObjectMeta: metav1.ObjectMeta{Name: "colin", InstanceID: "user-1", CreatedAt: created}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "lex", InstanceID: "user-2"}},
+	}
+
+	tests := []struct {
+		tableOpts *metav1.TableOptions
+		wantCols  string
+	}{
+		{tableOpts: nil, wantCols: "Name,InstanceID,Age"},
+		{tableOpts: &metav1.TableOptions{NoHeaders: true}, wantCols: ""},
+	}
+
+	for _, tt := range tests {
+		c, w := newTestContext(http.MethodGet, "/v1/users?limit=2", map[string]string{"Accept": "application/json;as=Table"})
+
+		opts := metav1.ListOptions{Limit: 2}
+		WriteListResponse(c, &opts, tt.tableOpts, 5, items)
+
+		var table metav1.Table
+		if err := json.Unmarshal(w.Body.Bytes(), &table); err != nil {
+			t.Fatalf("unmarshal table has an error: %v\n", err)
+		}
+
+		names := make([]string, 0, len(table.ColumnDefinitions))
+		for _, col := range table.ColumnDefinitions {
+			names = append(names, col.Name)
+		}
+
+		if table.Type != "Table" || table.TotalCount != 5 || strings.Join(names, ",") != tt.wantCols {
+			t.Errorf("table has an error: want columns:%s got:%s\n", tt.wantCols, w.Body.String())
+		}
+
+		want := [][]interface{}{{"colin", "user-1", "120m"}, {"lex", "user-2", "<unknown>"}}
+		if len(table.Rows) != len(want) || !reflect.DeepEqual(table.Rows[0].Cells, want[0]) || !reflect.DeepEqual(table.Rows[1].Cells, want[1]) {
+			t.Errorf("table rows has an error: want:%v got:%s\n", want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/patch"
+	"github.com/neee333ko/errors"
+)
+
+// ApplyPatch applies the JSON Patch or JSON Merge Patch of the request body,
+// selected by its Content-Type, to obj in place. Changes of the immutable
+// ObjectMeta fields are rejected and the patched obj is validated before it
+// may be persisted. Errors are coded with ErrBind, ErrUnsupportedMediaType,
+// ErrPatch or ErrValidation.
+func ApplyPatch(c *gin.Context, obj interface{}) error {
+	patchType := c.ContentType()
+	if patchType != patch.JSONPatchType && patchType != patch.MergePatchType {
+		return errors.WithCode(ErrUnsupportedMediaType, "unsupported patch type "+patchType)
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return errors.WrapC(err, ErrBind, err.Error())
+	}
+
+	var old *metav1.ObjectMeta
+	if accessor, ok := obj.(metav1.ObjectAccessor); ok {
+		if meta, ok := accessor.GetObject().(*metav1.ObjectMeta); ok {
+			snapshot := *meta
+			old = &snapshot
+		}
+	}
+
+	if err := patch.Apply(obj, patchType, data); err != nil {
+		if errors.Is(err, patch.ErrInvalidPatch) {
+			return errors.WrapC(err, ErrBind, err.Error())
+		}
+
+		return errors.WrapC(err, ErrPatch, err.Error())
+	}
+
+	if errs := patch.ValidateImmutable(old, obj); len(errs) != 0 {
+		return errors.WrapC(errs.ToAggregate(), ErrValidation, "immutable fields changed")
+	}
+
+	if errs := Validate(c, obj); len(errs) != 0 {
+		return errors.WrapC(errs.ToAggregate(), ErrValidation, "validation failed")
+	}
+
+	return nil
+}
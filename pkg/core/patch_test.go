@@ -0,0 +1,48 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/patch"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		wantStatus  int
+		wantName    string
+	}{
+		{contentType: patch.MergePatchType, body: `{"metadata":{"name":"lex"}}`, wantStatus: http.StatusOK, wantName: "lex"},
+		{contentType: patch.JSONPatchType, body: `[{"op":"replace","path":"/metadata/name","value":"lex"}]`, wantStatus: http.StatusOK, wantName: "lex"},
+		{contentType: "application/json", body: `{"metadata":{"name":"lex"}}`, wantStatus: http.StatusUnsupportedMediaType},
+		{contentType: patch.JSONPatchType, body: `{"op":`, wantStatus: http.StatusBadRequest},
+		{contentType: patch.JSONPatchType, body: `[{"op":"test","path":"/metadata/name","value":"lex"}]`, wantStatus: http.StatusUnprocessableEntity},
+		{contentType: patch.MergePatchType, body: `{"metadata":{"instanceID":"user-2"}}`, wantStatus: http.StatusUnprocessableEntity},
+		{contentType: patch.MergePatchType, body: `{"metadata":{"name":"-lex"}}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/v1/users/colin", strings.NewReader(tt.body))
+		c.Request.Header.Set("Content-Type", tt.contentType)
+
+		obj := &testObject{ObjectMeta: metav1.ObjectMeta{ID: 1, InstanceID: "user-1", Name: "colin"}}
+
+		if err := ApplyPatch(c, obj); err != nil {
+			WriteResponse(c, err, nil)
+		} else {
+			c.Status(http.StatusOK)
+		}
+
+		if w.Code != tt.wantStatus || (tt.wantName != "" && obj.Name != tt.wantName) {
+			t.Errorf("ApplyPatch(%s) has an error: want:%d,%s got:%d,%s %s\n", tt.body, tt.wantStatus, tt.wantName, w.Code, obj.Name, w.Body.String())
+		}
+	}
+}
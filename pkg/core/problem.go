@@ -0,0 +1,125 @@
+package core
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+const ProblemContentType = "application/problem+json"
+
+type ResponseMode int32
+
+const (
+	// ResponseModeDefault writes errors as Response unless the client accepts
+	// application/problem+json.
+	ResponseModeDefault ResponseMode = iota
+	// ResponseModeProblem writes all errors as RFC 7807 problem details.
+	ResponseModeProblem
+)
+
+var responseMode int32
+
+// SetResponseMode selects how errors are written for all requests.
+func SetResponseMode(mode ResponseMode) {
+	atomic.StoreInt32(&responseMode, int32(mode))
+}
+
+func GetResponseMode() ResponseMode {
+	return ResponseMode(atomic.LoadInt32(&responseMode))
+}
+
+// Problem is the RFC 7807 problem details object, Extensions are written as
+// top-level members next to the standard ones.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+
+	return json.Marshal(m)
+}
+
+// NewProblem converts a coded error into problem details for the request of c.
+func NewProblem(c *gin.Context, err error) *Problem {
+	coder := errors.ParseCoder(err)
+
+	problem := &Problem{
+		Type:     coder.Reference(),
+		Title:    http.StatusText(coder.HttpStatus()),
+		Status:   coder.HttpStatus(),
+		Detail:   localizedMessage(c, coder),
+		Instance: c.Request.URL.Path,
+		Extensions: map[string]interface{}{
+			"code": coder.Code(),
+		},
+	}
+
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+
+	if details := ErrorDetails(err); len(details) > 0 {
+		problem.Extensions["errors"] = details
+	}
+
+	if requestID := GetRequestID(c); requestID != "" {
+		problem.Extensions["requestID"] = requestID
+	}
+
+	return problem
+}
+
+func wantsProblem(c *gin.Context) bool {
+	if GetResponseMode() == ResponseModeProblem {
+		return true
+	}
+
+	for _, r := range parseAccept(c.GetHeader("Accept")) {
+		if r.mediaType == ProblemContentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeProblem(c *gin.Context, err error) {
+	problem := NewProblem(c, err)
+
+	data, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		log.L(c).Errorf("marshal problem details failed: %s\n", marshalErr.Error())
+		c.Status(problem.Status)
+
+		return
+	}
+
+	c.Data(problem.Status, ProblemContentType, data)
+}
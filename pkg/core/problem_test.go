@@ -0,0 +1,69 @@
+package core
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+func TestWriteResponseProblem(t *testing.T) {
+	defer SetResponseMode(ResponseModeDefault)
+
+	tests := []struct {
+		mode        ResponseMode
+		accept      string
+		wantProblem bool
+	}{
+		{mode: ResponseModeDefault, accept: "application/json", wantProblem: false},
+		{mode: ResponseModeDefault, accept: "application/problem+json, application/json;q=0.5", wantProblem: true},
+		{mode: ResponseModeDefault, accept: "application/json, application/problem+json;q=0", wantProblem: false},
+		{mode: ResponseModeProblem, accept: "", wantProblem: true},
+	}
+
+	err := errors.WrapC(field.Required(field.NewPath("name")), ErrValidation, "invalid user")
+
+	for _, tt := range tests {
+		SetResponseMode(tt.mode)
+
+		c, w := newTestContext(http.MethodPost, "/v1/users?dryRun=All", map[string]string{"Accept": tt.accept})
+		c.Set(log.KeyRequestID, "req-123")
+		WriteResponse(c, err, nil)
+
+		if isProblem := w.Header().Get("Content-Type") == ProblemContentType; isProblem != tt.wantProblem {
+			t.Fatalf("WriteResponse has an error: want problem:%v got:%s\n", tt.wantProblem, w.Header().Get("Content-Type"))
+		}
+
+		if !tt.wantProblem {
+			continue
+		}
+
+		var problem map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("invalid problem body %s: %v\n", w.Body.String(), err)
+		}
+
+		want := map[string]interface{}{
+			"type":      "about:blank",
+			"title":     "Unprocessable Entity",
+			"status":    float64(http.StatusUnprocessableEntity),
+			"detail":    "Validation failed",
+			"instance":  "/v1/users",
+			"code":      float64(ErrValidation),
+			"requestID": "req-123",
+		}
+
+		for k, v := range want {
+			if problem[k] != v {
+				t.Errorf("problem member %s has an error: want:%v got:%v\n", k, v, problem[k])
+			}
+		}
+
+		if errs, ok := problem["errors"].([]interface{}); !ok || len(errs) != 1 {
+			t.Errorf("problem should carry field errors: %s\n", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/util/iputil"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// Rate limiting algorithms.
+const (
+	TokenBucket   = "token-bucket"
+	SlidingWindow = "sliding-window"
+)
+
+// Rate limiting keys.
+const (
+	KeyByIP        = "ip"
+	KeyByPrincipal = "principal"
+)
+
+// RateLimitResult is the outcome of one RateLimiter.Allow call.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is fully restored.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when
+	// Allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimiter counts the requests of every key.
+type RateLimiter interface {
+	Allow(key string, now time.Time) RateLimitResult
+}
+
+// RateLimitKeyFunc returns the key a request is counted for.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RemoteIPKey counts requests per client IP.
+func RemoteIPKey(c *gin.Context) string {
+	return "ip:" + iputil.RemoteIP(c.Request)
+}
+
+// PrincipalKey counts requests per authenticated user, anonymous requests per
+// client IP.
+func PrincipalKey(c *gin.Context) string {
+	if username := c.GetString(log.KeyUsername); username != "" {
+		return "user:" + username
+	}
+
+	return RemoteIPKey(c)
+}
+
+// RateLimitRule allows Limit requests per Period for every key.
+type RateLimitRule struct {
+	// Algorithm is token-bucket or sliding-window, token-bucket by default.
+	Algorithm string          `json:"algorithm" yaml:"algorithm"`
+	Limit     int             `json:"limit" yaml:"limit"`
+	Period    metav1.Duration `json:"period" yaml:"period"`
+	// Key is ip or principal, ip by default.
+	Key string `json:"key" yaml:"key"`
+}
+
+// RateLimitConfig configures the RateLimit middleware. Routes are keyed by
+// the route path, e.g. "/v1/users/:name", optionally prefixed by the method,
+// e.g. "POST /v1/users". Requests of other routes fall under Default, they
+// are not limited when it is nil.
+type RateLimitConfig struct {
+	Default *RateLimitRule           `json:"default" yaml:"default"`
+	Routes  map[string]RateLimitRule `json:"routes" yaml:"routes"`
+}
+
+func (r *RateLimitRule) validate() error {
+	if r.Limit <= 0 {
+		return errors.Errorf("rate limit must be positive, got %d", r.Limit)
+	}
+
+	if r.Period.Duration <= 0 {
+		return errors.Errorf("rate limit period must be positive, got %s", r.Period.Duration)
+	}
+
+	switch r.Algorithm {
+	case "", TokenBucket, SlidingWindow:
+	default:
+		return errors.Errorf("unsupported rate limit algorithm %q", r.Algorithm)
+	}
+
+	switch r.Key {
+	case "", KeyByIP, KeyByPrincipal:
+	default:
+		return errors.Errorf("unsupported rate limit key %q", r.Key)
+	}
+
+	return nil
+}
+
+type rateLimit struct {
+	limiter RateLimiter
+	key     RateLimitKeyFunc
+}
+
+func newRateLimit(r RateLimitRule) (*rateLimit, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+
+	l := &rateLimit{key: RemoteIPKey}
+	if r.Key == KeyByPrincipal {
+		l.key = PrincipalKey
+	}
+
+	if r.Algorithm == SlidingWindow {
+		l.limiter = NewSlidingWindowLimiter(r.Limit, r.Period.Duration)
+	} else {
+		l.limiter = NewTokenBucketLimiter(r.Limit, r.Period.Duration)
+	}
+
+	return l, nil
+}
+
+// RateLimit limits requests as configured by config. Every response carries
+// the RateLimit-* headers, rejected requests also Retry-After and an
+// ErrTooManyRequests response written by WriteResponse.
+func RateLimit(config RateLimitConfig) (gin.HandlerFunc, error) {
+	var def *rateLimit
+
+	if config.Default != nil {
+		l, err := newRateLimit(*config.Default)
+		if err != nil {
+			return nil, errors.Wrapf(err, "default rate limit")
+		}
+
+		def = l
+	}
+
+	routes := make(map[string]*rateLimit, len(config.Routes))
+
+	for route, rule := range config.Routes {
+		l, err := newRateLimit(rule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rate limit of %s", route)
+		}
+
+		routes[route] = l
+	}
+
+	return func(c *gin.Context) {
+		l, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			if l, ok = routes[c.FullPath()]; !ok {
+				l = def
+			}
+		}
+
+		if l == nil {
+			c.Next()
+
+			return
+		}
+
+		result := l.limiter.Allow(l.key(c), time.Now())
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(seconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header(RetryAfterHeader, strconv.Itoa(seconds(result.RetryAfter)))
+			WriteResponse(c, errors.WithCode(ErrTooManyRequests, fmt.Sprintf("rate limit of %d requests exceeded", result.Limit)), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// rateLimitState holds per key states in two generations. The older
+// generation is dropped every idle period, so a state is kept for at least
+// idle after its last use and keys are never scanned.
+type rateLimitState[T any] struct {
+	mu       sync.Mutex
+	idle     time.Duration
+	current  map[string]*T
+	previous map[string]*T
+	rotated  time.Time
+}
+
+func newRateLimitState[T any](idle time.Duration) rateLimitState[T] {
+	return rateLimitState[T]{idle: idle, current: make(map[string]*T)}
+}
+
+func (s *rateLimitState[T]) get(key string, now time.Time) *T {
+	if now.Sub(s.rotated) >= s.idle {
+		s.previous, s.current = s.current, make(map[string]*T)
+		s.rotated = now
+	}
+
+	if st, ok := s.current[key]; ok {
+		return st
+	}
+
+	st, ok := s.previous[key]
+	if ok {
+		delete(s.previous, key)
+	} else {
+		st = new(T)
+	}
+
+	s.current[key] = st
+
+	return st
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucketLimiter allows bursts of limit requests, the bucket refills at
+// limit tokens per period.
+type TokenBucketLimiter struct {
+	limit int
+	rate  float64
+	state rateLimitState[bucket]
+}
+
+func NewTokenBucketLimiter(limit int, period time.Duration) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		limit: limit,
+		rate:  float64(limit) / period.Seconds(),
+		state: newRateLimitState[bucket](period),
+	}
+}
+
+func (l *TokenBucketLimiter) Allow(key string, now time.Time) RateLimitResult {
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+
+	b := l.state.get(key, now)
+	if b.last.IsZero() {
+		b.tokens = float64(l.limit)
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(l.limit), b.tokens+elapsed*l.rate)
+	}
+
+	b.last = now
+
+	result := RateLimitResult{Limit: l.limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.duration(1 - b.tokens)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = l.duration(float64(l.limit) - b.tokens)
+
+	return result
+}
+
+// duration returns the time needed to refill tokens.
+func (l *TokenBucketLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+type window struct {
+	start    time.Time
+	previous int
+	current  int
+}
+
+// SlidingWindowLimiter allows limit requests in any window, the requests of
+// the previous fixed window are weighted by its overlap with the sliding one.
+type SlidingWindowLimiter struct {
+	limit  int
+	window time.Duration
+	state  rateLimitState[window]
+}
+
+func NewSlidingWindowLimiter(limit int, period time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{
+		limit:  limit,
+		window: period,
+		state:  newRateLimitState[window](2 * period),
+	}
+}
+
+func (l *SlidingWindowLimiter) Allow(key string, now time.Time) RateLimitResult {
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+
+	w := l.state.get(key, now)
+
+	start := now.Truncate(l.window)
+	switch {
+	case start.Equal(w.start):
+	case start.Sub(w.start) == l.window:
+		w.previous, w.current = w.current, 0
+	default:
+		w.previous, w.current = 0, 0
+	}
+
+	w.start = start
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(l.window)
+	count := float64(w.previous)*weight + float64(w.current)
+
+	result := RateLimitResult{Limit: l.limit}
+	if count+1 <= float64(l.limit) {
+		w.current++
+		count++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.retryAfter(w, elapsed)
+	}
+
+	result.Remaining = int(math.Max(0, math.Floor(float64(l.limit)-count)))
+	// requests of the current window still count during the next one
+	result.Reset = l.window - elapsed
+	if w.current > 0 {
+		result.Reset += l.window
+	}
+
+	return result
+}
+
+// retryAfter returns the time until the weighted count of w drops below the
+// limit.
+func (l *SlidingWindowLimiter) retryAfter(w *window, elapsed time.Duration) time.Duration {
+	if w.current < l.limit {
+		// previous*(1-t/window)+current <= limit-1
+		t := float64(l.window) * (1 - float64(l.limit-1-w.current)/float64(w.previous))
+
+		return time.Duration(t) - elapsed
+	}
+
+	// the current window becomes the previous one
+	t := float64(l.window) * (1 - float64(l.limit-1)/float64(w.current))
+
+	return l.window - elapsed + time.Duration(t)
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/log"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRateLimiters(t *testing.T) {
+	t0 := time.Unix(100, 0)
+
+	tests := []struct {
+		name    string
+		limiter RateLimiter
+		key     string
+		at      time.Duration
+		want    RateLimitResult
+	}{
+		{name: "token bucket", limiter: NewTokenBucketLimiter(2, time.Second), key: "a", at: 0, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}},
+		{name: "token bucket", key: "a", at: 0, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}},
+		{name: "token bucket", key: "a", at: 0, want: RateLimitResult{Limit: 2, Remaining: 0, Reset: time.Second, RetryAfter: 500 * time.Millisecond}},
+		{name: "token bucket", key: "b", at: 0, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}},
+		{name: "token bucket", key: "a", at: 500 * time.Millisecond, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}},
+		{name: "sliding window", limiter: NewSlidingWindowLimiter(2, time.Second), key: "a", at: 0, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 2 * time.Second}},
+		{name: "sliding window", key: "a", at: 0, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{name: "sliding window", key: "a", at: 500 * time.Millisecond, want: RateLimitResult{Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: time.Second}},
+		{name: "sliding window", key: "a", at: 1500 * time.Millisecond, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond}},
+		{name: "sliding window", key: "a", at: 1500 * time.Millisecond, want: RateLimitResult{Limit: 2, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{name: "sliding window", key: "a", at: 2 * time.Second, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}},
+		{name: "sliding window", key: "a", at: 5 * time.Second, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 2 * time.Second}},
+	}
+
+	var limiter RateLimiter
+
+	for i, tt := range tests {
+		if tt.limiter != nil {
+			limiter = tt.limiter
+		}
+
+		if got := limiter.Allow(tt.key, t0.Add(tt.at)); got != tt.want {
+			t.Errorf("%s #%d has an error: want:%+v got:%+v\n", tt.name, i, tt.want, got)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler, err := RateLimit(RateLimitConfig{
+		Default: &RateLimitRule{Limit: 2, Period: metav1.Duration{Duration: time.Minute}},
+		Routes: map[string]RateLimitRule{
+			"POST /v1/users":  {Algorithm: SlidingWindow, Limit: 1, Period: metav1.Duration{Duration: time.Minute}, Key: KeyByPrincipal},
+			"/v1/users/:name": {Limit: 100, Period: metav1.Duration{Duration: time.Minute}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RateLimit has an error: %v\n", err)
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(log.KeyUsername, c.GetHeader("X-User"))
+	}, handler)
+	r.GET("/v1/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/v1/users", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	r.GET("/v1/users/:name", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		method        string
+		path          string
+		ip            string
+		user          string
+		wantStatus    int
+		wantLimit     string
+		wantRemaining string
+	}{
+		{method: http.MethodGet, path: "/v1/users", ip: "10.0.0.1", wantStatus: http.StatusOK, wantLimit: "2", wantRemaining: "1"},
+		{method: http.MethodGet, path: "/v1/users", ip: "10.0.0.1", wantStatus: http.StatusOK, wantLimit: "2", wantRemaining: "0"},
+		{method: http.MethodGet, path: "/v1/users", ip: "10.0.0.1", wantStatus: http.StatusTooManyRequests, wantLimit: "2", wantRemaining: "0"},
+		{method: http.MethodGet, path: "/v1/users", ip: "10.0.0.2", wantStatus: http.StatusOK, wantLimit: "2", wantRemaining: "1"},
+		{method: http.MethodGet, path: "/v1/users/colin", ip: "10.0.0.1", wantStatus: http.StatusOK, wantLimit: "100", wantRemaining: "99"},
+		{method: http.MethodPost, path: "/v1/users", ip: "10.0.0.1", user: "colin", wantStatus: http.StatusCreated, wantLimit: "1", wantRemaining: "0"},
+		{method: http.MethodPost, path: "/v1/users", ip: "10.0.0.2", user: "colin", wantStatus: http.StatusTooManyRequests, wantLimit: "1", wantRemaining: "0"},
+		{method: http.MethodPost, path: "/v1/users", ip: "10.0.0.1", user: "lex", wantStatus: http.StatusCreated, wantLimit: "1", wantRemaining: "0"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.RemoteAddr = tt.ip + ":1234"
+		req.Header.Set("X-User", tt.user)
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus || w.Header().Get(RateLimitLimitHeader) != tt.wantLimit ||
+			w.Header().Get(RateLimitRemainingHeader) != tt.wantRemaining || w.Header().Get(RateLimitResetHeader) == "" {
+			t.Errorf("%s %s (%s, %s) has an error: want:%d,%s,%s got:%d,%v\n", tt.method, tt.path, tt.ip, tt.user,
+				tt.wantStatus, tt.wantLimit, tt.wantRemaining, w.Code, w.Header())
+		}
+
+		if tt.wantStatus != http.StatusTooManyRequests {
+			continue
+		}
+
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != ErrTooManyRequests || w.Header().Get(RetryAfterHeader) == "" {
+			t.Errorf("%s %s rejection has an error: want:%d got:%s,%v\n", tt.method, tt.path, ErrTooManyRequests, w.Body.String(), w.Header())
+		}
+	}
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	tests := []struct {
+		config  RateLimitConfig
+		wantErr bool
+	}{
+		{config: RateLimitConfig{}, wantErr: false},
+		{config: RateLimitConfig{Default: &RateLimitRule{Limit: 1, Period: metav1.Duration{Duration: time.Second}, Algorithm: TokenBucket, Key: KeyByIP}}, wantErr: false},
+		{config: RateLimitConfig{Default: &RateLimitRule{Limit: 0, Period: metav1.Duration{Duration: time.Second}}}, wantErr: true},
+		{config: RateLimitConfig{Default: &RateLimitRule{Limit: 1}}, wantErr: true},
+		{config: RateLimitConfig{Routes: map[string]RateLimitRule{"/v1/users": {Limit: 1, Period: metav1.Duration{Duration: time.Second}, Algorithm: "leaky"}}}, wantErr: true},
+		{config: RateLimitConfig{Routes: map[string]RateLimitRule{"/v1/users": {Limit: 1, Period: metav1.Duration{Duration: time.Second}, Key: "header"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if _, err := RateLimit(tt.config); (err != nil) != tt.wantErr {
+			t.Errorf("RateLimit(%+v) has an error: want:%v got:%v\n", tt.config, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRateLimitConfigDecode(t *testing.T) {
+	tests := []struct {
+		data   string
+		decode func(data []byte, v interface{}) error
+	}{
+		{data: `{"default":{"limit":10,"period":"1m"},"routes":{"POST /v1/users":{"limit":1,"period":"1s"}}}`, decode: json.Unmarshal},
+		{data: `{"default":{"limit":10,"period":60000000000},"routes":{"POST /v1/users":{"limit":1,"period":"1s"}}}`, decode: json.Unmarshal},
+		{data: "default:\n  limit: 10\n  period: 1m\nroutes:\n  POST /v1/users:\n    limit: 1\n    period: 1s\n", decode: yaml.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		var config RateLimitConfig
+		if err := tt.decode([]byte(tt.data), &config); err != nil {
+			t.Errorf("decode %s has an error: %v\n", tt.data, err)
+
+			continue
+		}
+
+		if config.Default.Period.Duration != time.Minute || config.Routes["POST /v1/users"].Period.Duration != time.Second {
+			t.Errorf("decode %s has an error: got:%+v\n", tt.data, config)
+		}
+	}
+}
+
+func TestRateLimitStateEviction(t *testing.T) {
+	t0 := time.Unix(100, 0)
+	s := newRateLimitState[int](time.Second)
+
+	*s.get("a", t0) = 1
+	*s.get("b", t0) = 1
+
+	// a is used within idle, b is dropped after two rotations
+	tests := []struct {
+		key  string
+		at   time.Duration
+		want int
+	}{
+		{key: "a", at: 1500 * time.Millisecond, want: 1},
+		{key: "a", at: 2600 * time.Millisecond, want: 1},
+		{key: "b", at: 2600 * time.Millisecond, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := *s.get(tt.key, t0.Add(tt.at)); got != tt.want {
+			t.Errorf("get(%s, %s) has an error: want:%d got:%d\n", tt.key, tt.at, tt.want, got)
+		}
+	}
+
+	if len(s.current)+len(s.previous) != 2 {
+		t.Errorf("states have an error: want:2 got:%d\n", len(s.current)+len(s.previous))
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+// Recovery recovers handler panics, logs them with their stack and answers
+// with an ErrInternal response written by WriteResponse. Panics caused by
+// clients that went away are only logged as a warning, http.ErrAbortHandler
+// is panicked again so that net/http aborts the connection.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			if err, ok := r.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				c.Abort()
+				panic(r)
+			}
+
+			if isBrokenPipe(r) {
+				log.L(c).Warnf("client disconnected from %s %s: %v\n", c.Request.Method, c.Request.URL.Path, r)
+				c.Abort()
+
+				return
+			}
+
+			log.L(c).Errorf("panic recovered in %s %s: %v\n%s", c.Request.Method, c.Request.URL.Path, r, debug.Stack())
+
+			if c.Writer.Written() {
+				c.Abort()
+
+				return
+			}
+
+			WriteResponse(c, errors.WithCode(ErrInternal, fmt.Sprintf("panic: %v", r)), nil)
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
+
+// isBrokenPipe reports whether a panic comes from writing to a closed
+// connection.
+func isBrokenPipe(r interface{}) bool {
+	err, ok := r.(error)
+	if !ok {
+		return false
+	}
+
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+)
+
+func TestRecovery(t *testing.T) {
+	brokenPipe := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+
+	tests := []struct {
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantCode   int
+	}{
+		{handler: func(c *gin.Context) { panic("boom") }, wantStatus: http.StatusInternalServerError, wantCode: ErrInternal},
+		{handler: func(c *gin.Context) { panic(brokenPipe) }, wantStatus: http.StatusOK},
+		{
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusAccepted)
+				c.Writer.WriteHeaderNow()
+				panic("late")
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(w)
+		engine.Use(RequestID(), Recovery())
+		engine.GET("/", tt.handler)
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("Recovery has an error: want:%d got:%d\n", tt.wantStatus, w.Code)
+		}
+
+		if tt.wantCode == 0 {
+			continue
+		}
+
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != tt.wantCode || resp.RequestID == "" {
+			t.Errorf("Recovery body has an error: %s\n", w.Body.String())
+		}
+	}
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("Recovery has an error: want panic:%v got:%v\n", http.ErrAbortHandler, r)
+		}
+	}()
+
+	w := httptest.NewRecorder()
+	_, engine := gin.CreateTestContext(w)
+	engine.Use(Recovery())
+	engine.GET("/", func(c *gin.Context) { panic(http.ErrAbortHandler) })
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/util/idutil"
+	"github.com/neee333ko/log"
+)
+
+const XRequestIDKey = "X-Request-ID"
+
+var requestIDRegexp *regexp.Regexp = regexp.MustCompile(`^[0-9a-zA-Z._:-]{1,128}$`)
+
+// RequestID accepts the X-Request-ID of the client, or generates one, and
+// exposes it to the response headers, log.L and the error bodies written by
+// WriteResponse.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid := c.GetHeader(XRequestIDKey)
+		if !requestIDRegexp.MatchString(rid) {
+			rid = idutil.GetUUID36("")
+		}
+
+		c.Set(log.KeyRequestID, rid)
+		// log.L looks the request ID up by its plain string key.
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), log.KeyRequestID, rid))
+		c.Header(XRequestIDKey, rid)
+
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID set by the RequestID middleware.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(log.KeyRequestID)
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+func TestRequestID(t *testing.T) {
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/v1/users", func(c *gin.Context) {
+		if c.Request.Context().Value(log.KeyRequestID) != GetRequestID(c) {
+			t.Errorf("request ID is not propagated to the request context\n")
+		}
+
+		WriteResponse(c, errors.WithCode(ErrBind, "bad request"), nil)
+	})
+
+	tests := []struct {
+		header   string
+		wantSame bool
+	}{
+		{header: "client-id-123", wantSame: true},
+		{header: "", wantSame: false},
+		{header: "bad id with spaces", wantSame: false},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		req.Header.Set(XRequestIDKey, tt.header)
+		r.ServeHTTP(w, req)
+
+		rid := w.Header().Get(XRequestIDKey)
+		if rid == "" || (rid == tt.header) != tt.wantSame {
+			t.Errorf("RequestID has an error: header:%q got:%q\n", tt.header, rid)
+		}
+
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.RequestID != rid {
+			t.Errorf("error body should carry the request ID %s: %s\n", rid, w.Body.String())
+		}
+	}
+}
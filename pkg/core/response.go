@@ -4,27 +4,114 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/validation/field"
 	"github.com/neee333ko/errors"
 	"github.com/neee333ko/log"
 )
 
+// ErrorCodeKey is the gin context key of the code of the error written by
+// WriteResponse, e.g. for metrics.
+const ErrorCodeKey = "core.errorCode"
+
 type Response struct {
-	Code      int    `json:"code"`
-	Message   string `json:"message"`
-	Reference string `json:"reference"`
+	Code      int           `json:"code"`
+	Message   string        `json:"message"`
+	Reference string        `json:"reference"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+	RequestID string        `json:"requestID,omitempty"`
+}
+
+// ErrorDetail describes one offending field of a request.
+type ErrorDetail struct {
+	Field    string      `json:"field"`
+	Type     string      `json:"type"`
+	BadValue interface{} `json:"badValue,omitempty"`
+	Detail   string      `json:"detail,omitempty"`
 }
 
+// WriteResponse writes data, or the coded error err, in the format negotiated
+// with the client.
 func WriteResponse(c *gin.Context, err error, data interface{}) {
-	if err != nil {
-		log.Errorf("%#+v\n", err)
-		coder := errors.ParseCoder(err)
-
-		c.JSON(coder.HttpStatus(), Response{
-			Code:      coder.Code(),
-			Message:   coder.Message(),
-			Reference: coder.Reference(),
-		})
+	s, negotiateErr := Negotiate(c)
+
+	if err == nil {
+		if negotiateErr != nil {
+			err = negotiateErr
+		} else if encodeErr := writeData(c, s, http.StatusOK, data); encodeErr != nil {
+			err = errors.WrapC(encodeErr, ErrEncode, encodeErr.Error())
+		} else {
+			return
+		}
+	}
+
+	log.L(c).Errorf("%#+v\n", err)
+	c.Set(ErrorCodeKey, errors.ParseCoder(err).Code())
+
+	if wantsProblem(c) {
+		writeProblem(c, err)
+
+		return
+	}
+
+	if negotiateErr != nil {
+		s = jsonSerializer{}
+	}
+
+	coder := errors.ParseCoder(err)
+
+	if encodeErr := writeData(c, s, coder.HttpStatus(), newResponse(c, err)); encodeErr != nil {
+		log.L(c).Errorf("encode error response failed: %s\n", encodeErr.Error())
+		c.Status(coder.HttpStatus())
 	}
+}
+
+func newResponse(c *gin.Context, err error) *Response {
+	coder := errors.ParseCoder(err)
+
+	return &Response{
+		Code:      coder.Code(),
+		Message:   localizedMessage(c, coder),
+		Reference: coder.Reference(),
+		Details:   ErrorDetails(err),
+		RequestID: GetRequestID(c),
+	}
+}
+
+// ErrorDetails returns the field errors carried by err, e.g. a coded error
+// wrapping a *field.Error or the aggregate of a field.ErrorList.
+func ErrorDetails(err error) []ErrorDetail {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch e := err.(type) {
+		case *field.Error:
+			return []ErrorDetail{newErrorDetail(e)}
+		case errors.Aggregate:
+			flattened := errors.Flatten(e)
+			if flattened == nil {
+				continue
+			}
+
+			details := make([]ErrorDetail, 0, len(flattened.Errors()))
 
-	c.JSON(http.StatusOK, data)
+			for _, fe := range flattened.Errors() {
+				if fe, ok := fe.(*field.Error); ok {
+					details = append(details, newErrorDetail(fe))
+				}
+			}
+
+			if len(details) > 0 {
+				return details
+			}
+		}
+	}
+
+	return nil
+}
+
+func newErrorDetail(e *field.Error) ErrorDetail {
+	return ErrorDetail{
+		Field:    e.Field(),
+		Type:     string(e.Type()),
+		BadValue: e.BadValue(),
+		Detail:   e.Detail(),
+	}
 }
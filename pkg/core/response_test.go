@@ -0,0 +1,92 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/validation"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+	"github.com/neee333ko/errors"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestContext(method, target string, header map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, nil)
+
+	for k, v := range header {
+		c.Request.Header.Set(k, v)
+	}
+
+	return c, w
+}
+
+func TestWriteResponseDetails(t *testing.T) {
+	path := field.NewPath("metadata")
+
+	tests := []struct {
+		err        error
+		wantStatus int
+		wantCode   int
+		want       []ErrorDetail
+	}{
+		{
+			err: errors.WrapC(field.ErrorList{
+				field.Required(path.Child("name")),
+				field.Invalid(path.Child("extend").Key("level"), "high", "must be a number"),
+			}.ToAggregate(), ErrValidation, "invalid user"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   ErrValidation,
+			want: []ErrorDetail{
+				{Field: "metadata.name", Type: "Required"},
+				{Field: "metadata.extend[level]", Type: "Invalid", BadValue: "high", Detail: "must be a number"},
+			},
+		},
+		{
+			err: errors.WrapC(validation.NewValidator(&testObject{ObjectMeta: metav1.ObjectMeta{Name: "-bad"}}).Validate().ToAggregate(),
+				ErrValidation, "invalid object"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   ErrValidation,
+			want:       []ErrorDetail{{Field: "metadata.name", Type: "Invalid", BadValue: "-bad", Detail: "is not a valid name"}},
+		},
+		{
+			err:        errors.WrapC(field.NotSupport(field.NewPath("dryRun"), "Some", []string{"All"}), ErrBind, "bad request"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ErrBind,
+			want:       []ErrorDetail{{Field: "dryRun", Type: "NotSupport", BadValue: "Some", Detail: `supported values: "All"`}},
+		},
+		{
+			err:        errors.WithCode(ErrBind, "bad request"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ErrBind,
+		},
+	}
+
+	for _, tt := range tests {
+		c, w := newTestContext(http.MethodPost, "/v1/users", nil)
+		WriteResponse(c, tt.err, nil)
+
+		var resp Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response body %s: %v\n", w.Body.String(), err)
+		}
+
+		if w.Code != tt.wantStatus || resp.Code != tt.wantCode || len(resp.Details) != len(tt.want) {
+			t.Fatalf("WriteResponse has an error: want:%d,%d,%v got:%d,%s\n", tt.wantStatus, tt.wantCode, tt.want, w.Code, w.Body.String())
+		}
+
+		for i, want := range tt.want {
+			if resp.Details[i] != want {
+				t.Errorf("WriteResponse detail has an error: want:%+v got:%+v\n", want, resp.Details[i])
+			}
+		}
+	}
+}
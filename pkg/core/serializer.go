@@ -0,0 +1,292 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
+)
+
+// Serializer encodes response bodies and decodes request bodies of one media type.
+type Serializer interface {
+	ContentType() string
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+const (
+	FormatJSON    = "json"
+	FormatYAML    = "yaml"
+	FormatMsgPack = "msgpack"
+)
+
+// FormatQueryKey overrides the Accept header, e.g. ?format=yaml.
+const FormatQueryKey = "format"
+
+type serializerInfo struct {
+	format     string
+	mediaTypes []string
+	serializer Serializer
+}
+
+var (
+	serializersMu sync.RWMutex
+	serializers   = []serializerInfo{
+		{format: FormatJSON, mediaTypes: []string{"application/json"}, serializer: jsonSerializer{}},
+		{format: FormatYAML, mediaTypes: []string{"application/yaml", "application/x-yaml", "text/yaml"}, serializer: yamlSerializer{}},
+		{
+			format:     FormatMsgPack,
+			mediaTypes: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+			serializer: msgpackSerializer{},
+		},
+	}
+)
+
+// RegisterSerializer adds a serializer selected by ?format=format or by one of mediaTypes.
+func RegisterSerializer(format string, s Serializer, mediaTypes ...string) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+
+	serializers = append(serializers, serializerInfo{format: format, mediaTypes: mediaTypes, serializer: s})
+}
+
+func serializerFor(match func(info serializerInfo) bool) Serializer {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+
+	for _, info := range serializers {
+		if match(info) {
+			return info.serializer
+		}
+	}
+
+	return nil
+}
+
+// contentTypes returns the content types of the registered serializers.
+func contentTypes() []string {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+
+	types := make([]string, 0, len(serializers))
+	for _, info := range serializers {
+		types = append(types, info.serializer.ContentType())
+	}
+
+	return types
+}
+
+func serializerForFormat(format string) Serializer {
+	return serializerFor(func(info serializerInfo) bool { return info.format == format })
+}
+
+func serializerForMediaType(mediaType string) Serializer {
+	return serializerFor(func(info serializerInfo) bool {
+		for _, t := range info.mediaTypes {
+			if t == mediaType || (strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(t, strings.TrimSuffix(mediaType, "*"))) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+type mediaRange struct {
+	mediaType string
+	params    map[string]string
+	q         float64
+}
+
+// parseAccept returns the media ranges of an Accept header ordered by preference.
+func parseAccept(header string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+
+			delete(params, "q")
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, params: params, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	return ranges
+}
+
+// Negotiate selects the response serializer from ?format= or the Accept
+// header, JSON is used when neither is given. The error is coded with
+// ErrNotAcceptable.
+func Negotiate(c *gin.Context) (Serializer, error) {
+	if format := c.Query(FormatQueryKey); format != "" {
+		if s := serializerForFormat(format); s != nil {
+			return s, nil
+		}
+
+		return nil, errors.WithCode(ErrNotAcceptable, "unsupported format "+format)
+	}
+
+	accept := c.GetHeader("Accept")
+	if accept == "" {
+		return jsonSerializer{}, nil
+	}
+
+	for _, r := range parseAccept(accept) {
+		if r.mediaType == "*/*" {
+			return jsonSerializer{}, nil
+		}
+
+		if s := serializerForMediaType(r.mediaType); s != nil {
+			return s, nil
+		}
+	}
+
+	return nil, errors.WithCode(ErrNotAcceptable, "none of the accepted media types is supported: "+accept)
+}
+
+// Decode decodes the request body into obj according to its Content-Type,
+// JSON is assumed when it is missing. Errors are coded with ErrBind or
+// ErrUnsupportedMediaType.
+func Decode(c *gin.Context, obj interface{}) error {
+	s := Serializer(jsonSerializer{})
+
+	if contentType := c.ContentType(); contentType != "" {
+		if s = serializerForMediaType(contentType); s == nil {
+			return errors.WithCode(ErrUnsupportedMediaType, "unsupported content type "+contentType)
+		}
+	}
+
+	if err := s.Decode(c.Request.Body, obj); err != nil {
+		return errors.WrapC(err, ErrBind, err.Error())
+	}
+
+	return nil
+}
+
+func writeData(c *gin.Context, s Serializer, status int, data interface{}) error {
+	buf := bytes.NewBuffer(nil)
+	if err := s.Encode(buf, data); err != nil {
+		return err
+	}
+
+	c.Data(status, s.ContentType(), buf.Bytes())
+
+	return nil
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) ContentType() string { return "application/json; charset=utf-8" }
+
+func (jsonSerializer) Encode(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+func (jsonSerializer) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// yamlSerializer goes through JSON so that the json tags of API objects are
+// honored.
+type yamlSerializer struct{}
+
+func (yamlSerializer) ContentType() string { return "application/yaml; charset=utf-8" }
+
+func (yamlSerializer) Encode(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	resetYAMLStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+func (yamlSerializer) Decode(r io.Reader, v interface{}) error {
+	var generic interface{}
+	if err := yaml.NewDecoder(r).Decode(&generic); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// resetYAMLStyle drops the flow style kept from the JSON input.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, n := range node.Content {
+		resetYAMLStyle(n)
+	}
+}
+
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	h.RawToString = true
+	h.WriteExt = true
+
+	return h
+}()
+
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) ContentType() string { return "application/msgpack" }
+
+func (msgpackSerializer) Encode(w io.Writer, v interface{}) error {
+	return codec.NewEncoder(w, msgpackHandle).Encode(v)
+}
+
+func (msgpackSerializer) Decode(r io.Reader, v interface{}) error {
+	return codec.NewDecoder(r, msgpackHandle).Decode(v)
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testUser struct {
+	Name     string `json:"name"`
+	Nickname string `json:"nickname,omitempty"`
+	Age      int    `json:"age"`
+}
+
+func TestWriteResponseNegotiation(t *testing.T) {
+	user := &testUser{Name: "colin", Age: 18}
+
+	tests := []struct {
+		target      string
+		accept      string
+		wantStatus  int
+		contentType string
+		wantBody    string
+	}{
+		{target: "/v1/users/colin", accept: "", wantStatus: http.StatusOK, contentType: "application/json", wantBody: `{"name":"colin","age":18}`},
+		{target: "/v1/users/colin", accept: "text/html, */*;q=0.1", wantStatus: http.StatusOK, contentType: "application/json"},
+		{target: "/v1/users/colin", accept: "application/json;q=0.5, application/yaml", wantStatus: http.StatusOK, contentType: "application/yaml", wantBody: "name: colin\nage: 18\n"},
+		{target: "/v1/users/colin", accept: "application/x-msgpack", wantStatus: http.StatusOK, contentType: "application/msgpack"},
+		{target: "/v1/users/colin?format=yaml", accept: "application/json", wantStatus: http.StatusOK, contentType: "application/yaml"},
+		{target: "/v1/users/colin", accept: "text/html", wantStatus: http.StatusNotAcceptable, contentType: "application/json"},
+		{target: "/v1/users/colin?format=xml", accept: "", wantStatus: http.StatusNotAcceptable, contentType: "application/json"},
+	}
+
+	for _, tt := range tests {
+		c, w := newTestContext(http.MethodGet, tt.target, map[string]string{"Accept": tt.accept})
+		WriteResponse(c, nil, user)
+
+		if w.Code != tt.wantStatus || !strings.HasPrefix(w.Header().Get("Content-Type"), tt.contentType) {
+			t.Errorf("WriteResponse(%s, %s) has an error: want:%d,%s got:%d,%s\n",
+				tt.target, tt.accept, tt.wantStatus, tt.contentType, w.Code, w.Header().Get("Content-Type"))
+		}
+
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Errorf("WriteResponse(%s, %s) body has an error: want:%q got:%q\n", tt.target, tt.accept, tt.wantBody, w.Body.String())
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	msgpack := bytes.NewBuffer(nil)
+	_ = msgpackSerializer{}.Encode(msgpack, &testUser{Name: "colin", Age: 18})
+
+	tests := []struct {
+		contentType string
+		body        string
+		wantErr     int
+	}{
+		{contentType: "", body: `{"name":"colin","age":18}`},
+		{contentType: "application/json; charset=utf-8", body: `{"name":"colin","age":18}`},
+		{contentType: "application/yaml", body: "name: colin\nage: 18\n"},
+		{contentType: "application/msgpack", body: msgpack.String()},
+		{contentType: "text/plain", body: "colin", wantErr: http.StatusUnsupportedMediaType},
+		{contentType: "application/json", body: `{"name":`, wantErr: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(tt.body))
+		c.Request.Header.Set("Content-Type", tt.contentType)
+
+		var user testUser
+
+		err := Decode(c, &user)
+		if tt.wantErr != 0 {
+			WriteResponse(c, err, nil)
+
+			if w.Code != tt.wantErr {
+				t.Errorf("Decode(%s) has an error: want:%d got:%d\n", tt.contentType, tt.wantErr, w.Code)
+			}
+
+			continue
+		}
+
+		if err != nil || user != (testUser{Name: "colin", Age: 18}) {
+			t.Errorf("Decode(%s) has an error: got:%+v,%v\n", tt.contentType, user, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+const (
+	NDJSONContentType      = "application/x-ndjson"
+	EventStreamContentType = "text/event-stream"
+
+	LastEventIDKey = "Last-Event-ID"
+	// lastEventIDQueryKey is used by EventSource polyfills that cannot set headers.
+	lastEventIDQueryKey = "lastEventId"
+)
+
+var (
+	// StreamFlushInterval is how often buffered NDJSON items are flushed.
+	StreamFlushInterval = time.Second
+	// StreamKeepAliveInterval is how often a comment is sent on an idle
+	// event stream to keep proxies from closing it.
+	StreamKeepAliveInterval = 15 * time.Second
+)
+
+// Event is one item of a stream. ID and Type are only used by Server-Sent
+// Events.
+type Event struct {
+	ID     string
+	Type   string
+	Object interface{}
+}
+
+// StreamError is the final NDJSON frame of a stream that failed.
+type StreamError struct {
+	Error *Response `json:"error"`
+}
+
+// Producer sends the items of a stream until it is done or ctx is canceled,
+// it must stop sending once ctx is done. A returned error terminates the
+// stream with the coded error as the final frame.
+type Producer func(ctx context.Context, events chan<- Event) error
+
+// LastEventID returns the ID of the last event received by a reconnecting
+// EventSource, producers resume the stream after it.
+func LastEventID(c *gin.Context) string {
+	if id := c.GetHeader(LastEventIDKey); id != "" {
+		return id
+	}
+
+	return c.Query(lastEventIDQueryKey)
+}
+
+// StreamNDJSON streams the objects sent by produce as newline-delimited
+// JSON. The stream ends after opts.TimeoutSeconds when it is set.
+func StreamNDJSON(c *gin.Context, opts *metav1.ListOptions, produce Producer) {
+	c.Header("Content-Type", NDJSONContentType)
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+
+	writeLine := func(v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Writer.Write(append(data, '\n'))
+
+		return err
+	}
+
+	runStream(c, opts, produce, StreamFlushInterval, streamWriter{
+		event: func(e Event) error {
+			return writeLine(e.Object)
+		},
+		tick: func() error {
+			c.Writer.Flush()
+
+			return nil
+		},
+		error: func(resp *Response) error {
+			return writeLine(&StreamError{Error: resp})
+		},
+	})
+
+	c.Writer.Flush()
+}
+
+// StreamEvents pushes the events sent by produce as Server-Sent Events, an
+// error is sent as an "error" event. Events whose ID or Type contain a line
+// break end the stream with an ErrEncode error. The stream ends after
+// opts.TimeoutSeconds when it is set.
+func StreamEvents(c *gin.Context, opts *metav1.ListOptions, produce Producer) {
+	c.Header("Content-Type", EventStreamContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	writeEvent := func(id, typ string, v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		frame := ""
+		if id != "" {
+			frame += "id: " + id + "\n"
+		}
+
+		if typ != "" {
+			frame += "event: " + typ + "\n"
+		}
+
+		if _, err := fmt.Fprintf(c.Writer, "%sdata: %s\n\n", frame, data); err != nil {
+			return err
+		}
+
+		c.Writer.Flush()
+
+		return nil
+	}
+
+	runStream(c, opts, produce, StreamKeepAliveInterval, streamWriter{
+		event: func(e Event) error {
+			// a line break would start another field or event
+			if strings.ContainsAny(e.ID, "\r\n") || strings.ContainsAny(e.Type, "\r\n") {
+				err := errors.WithCode(ErrEncode, fmt.Sprintf("event id %q or type %q contains a line break", e.ID, e.Type))
+				if writeErr := writeEvent("", "error", newResponse(c, err)); writeErr != nil {
+					return writeErr
+				}
+
+				return err
+			}
+
+			return writeEvent(e.ID, e.Type, e.Object)
+		},
+		tick: func() error {
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return err
+			}
+
+			c.Writer.Flush()
+
+			return nil
+		},
+		error: func(resp *Response) error {
+			return writeEvent("", "error", resp)
+		},
+	})
+}
+
+type streamWriter struct {
+	event func(e Event) error
+	tick  func() error
+	error func(resp *Response) error
+}
+
+func runStream(c *gin.Context, opts *metav1.ListOptions, produce Producer, interval time.Duration, w streamWriter) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if opts != nil && opts.TimeoutSeconds > 0 {
+		ctx, cancel = context.WithTimeout(c.Request.Context(), time.Duration(opts.TimeoutSeconds)*time.Second)
+	} else {
+		ctx, cancel = context.WithCancel(c.Request.Context())
+	}
+	defer cancel()
+
+	events := make(chan Event)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		errc <- produce(ctx, events)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.tick(); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				if err := <-errc; err != nil && ctx.Err() == nil {
+					log.L(c).Errorf("%#+v\n", err)
+
+					if writeErr := w.error(newResponse(c, err)); writeErr != nil {
+						log.L(c).Errorf("write stream error failed: %s\n", writeErr.Error())
+					}
+				}
+
+				return
+			}
+
+			if err := w.event(e); err != nil {
+				log.L(c).Errorf("write stream event failed: %s\n", err.Error())
+
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/errors"
+)
+
+func sendAll(items []Event, err error) Producer {
+	return func(ctx context.Context, events chan<- Event) error {
+		for _, item := range items {
+			select {
+			case events <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		return err
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	items := []Event{{Object: &testUser{Name: "colin", Age: 18}}, {Object: &testUser{Name: "lex", Age: 20}}}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{
+			want: "{\"name\":\"colin\",\"age\":18}\n{\"name\":\"lex\",\"age\":20}\n",
+		},
+		{
+			err: errors.WithCode(ErrEncode, "marshal failed"),
+			want: "{\"name\":\"colin\",\"age\":18}\n{\"name\":\"lex\",\"age\":20}\n" +
+				"{\"error\":{\"code\":990005,\"message\":\"Error occurred while encoding the response\",\"reference\":\"\"}}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		c, w := newTestContext(http.MethodGet, "/v1/users", nil)
+		StreamNDJSON(c, nil, sendAll(items, tt.err))
+
+		if w.Header().Get("Content-Type") != NDJSONContentType || w.Body.String() != tt.want {
+			t.Errorf("StreamNDJSON has an error: want:%q got:%q\n", tt.want, w.Body.String())
+		}
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	items := []Event{{ID: "1", Type: "ADDED", Object: &testUser{Name: "colin", Age: 18}}, {ID: "2", Object: &testUser{Name: "lex"}}}
+
+	c, w := newTestContext(http.MethodGet, "/v1/users", map[string]string{LastEventIDKey: "0"})
+	StreamEvents(c, nil, sendAll(items, errors.WithCode(ErrEncode, "marshal failed")))
+
+	want := "id: 1\nevent: ADDED\ndata: {\"name\":\"colin\",\"age\":18}\n\n" +
+		"id: 2\ndata: {\"name\":\"lex\",\"age\":0}\n\n" +
+		"event: error\ndata: {\"code\":990005,\"message\":\"Error occurred while encoding the response\",\"reference\":\"\"}\n\n"
+
+	if w.Header().Get("Content-Type") != EventStreamContentType || w.Body.String() != want {
+		t.Errorf("StreamEvents has an error: want:%q got:%q\n", want, w.Body.String())
+	}
+
+	if id := LastEventID(c); id != "0" {
+		t.Errorf("LastEventID has an error: want:0 got:%s\n", id)
+	}
+}
+
+func TestStreamEventsLineBreak(t *testing.T) {
+	tests := []Event{
+		{ID: "1\nevent: DELETED", Object: &testUser{Name: "colin"}},
+		{ID: "1", Type: "ADDED\r\ndata: {}", Object: &testUser{Name: "colin"}},
+	}
+
+	for _, e := range tests {
+		c, w := newTestContext(http.MethodGet, "/v1/users", nil)
+		StreamEvents(c, nil, sendAll([]Event{e, {ID: "2", Object: &testUser{Name: "lex"}}}, nil))
+
+		if body := w.Body.String(); !strings.HasPrefix(body, "event: error\ndata: {\"code\":990005") || strings.Contains(body, "lex") {
+			t.Errorf("StreamEvents(%q, %q) has an error: got:%q\n", e.ID, e.Type, body)
+		}
+	}
+}
+
+func TestStreamDeadline(t *testing.T) {
+	interval := StreamKeepAliveInterval
+	StreamKeepAliveInterval = 100 * time.Millisecond
+
+	defer func() { StreamKeepAliveInterval = interval }()
+
+	c, w := newTestContext(http.MethodGet, "/v1/users?timeoutSeconds=1", nil)
+
+	var opts metav1.ListOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		t.Fatalf("bind query has an error: %v\n", err)
+	}
+
+	start := time.Now()
+	StreamEvents(c, &opts, func(ctx context.Context, events chan<- Event) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 3*time.Second {
+		t.Errorf("stream deadline has an error: want:1s got:%v\n", elapsed)
+	}
+
+	if body := w.Body.String(); !strings.HasPrefix(body, ": keep-alive\n\n") || strings.Contains(body, "error") {
+		t.Errorf("stream keep-alive has an error: got:%q\n", body)
+	}
+}
@@ -0,0 +1,59 @@
+package dryrun
+
+import (
+	"github.com/jinzhu/gorm"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/meta/v1/validation"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+)
+
+// IsDryRun reports whether the dryRun field of the request options asks for
+// a dry run. Any value does, so that an unrecognized one never persists.
+func IsDryRun(dryRun []string) bool {
+	return len(dryRun) != 0
+}
+
+// Run calls fn with db, or for a dry run with a transaction that is rolled
+// back once fn returns, so that hooks like ObjectMeta.BeforeCreate and the
+// database constraints are exercised without persisting anything. Objects
+// modified by fn hold the would-be result. fn is not called when dryRun is
+// rejected by validation.ValidateDryRun.
+func Run(db *gorm.DB, dryRun []string, fn func(tx *gorm.DB) error) error {
+	if errs := validation.ValidateDryRun(field.NewPath("dryRun"), dryRun); len(errs) != 0 {
+		return errs.ToAggregate()
+	}
+
+	if !IsDryRun(dryRun) {
+		return fn(db)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer tx.Rollback()
+
+	return fn(tx)
+}
+
+// Create creates obj, honoring the dry run of opts.
+func Create(db *gorm.DB, opts *metav1.CreateOptions, obj interface{}) error {
+	return Run(db, opts.DryRun, func(tx *gorm.DB) error {
+		return tx.Create(obj).Error
+	})
+}
+
+// Update saves obj, honoring the dry run of opts.
+func Update(db *gorm.DB, opts *metav1.UpdateOptions, obj interface{}) error {
+	return Run(db, opts.DryRun, func(tx *gorm.DB) error {
+		return tx.Save(obj).Error
+	})
+}
+
+// Patch saves the patched obj, honoring the dry run of opts.
+func Patch(db *gorm.DB, opts *metav1.PatchOptions, obj interface{}) error {
+	return Run(db, opts.DryRun, func(tx *gorm.DB) error {
+		return tx.Save(obj).Error
+	})
+}
@@ -0,0 +1,66 @@
+package dryrun
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+)
+
+type testUser struct {
+	metav1.ObjectMeta
+	Nickname string
+}
+
+func TestCreate(t *testing.T) {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		// go-sqlite3 needs cgo
+		t.Skipf("open database has an error: %v\n", err)
+	}
+	defer db.Close()
+
+	db.AutoMigrate(&testUser{})
+
+	tests := []struct {
+		dryRun    []string
+		name      string
+		wantErr   bool
+		wantCount int
+	}{
+		{dryRun: []string{metav1.DryRunAll}, name: "colin", wantCount: 0},
+		{dryRun: []string{"all"}, name: "colin", wantErr: true, wantCount: 0},
+		{dryRun: []string{metav1.DryRunAll, "Some"}, name: "colin", wantErr: true, wantCount: 0},
+		{dryRun: nil, name: "colin", wantCount: 1},
+		{dryRun: []string{metav1.DryRunAll}, name: "lex", wantCount: 1},
+	}
+
+	for _, tt := range tests {
+		user := &testUser{
+			ObjectMeta: metav1.ObjectMeta{Name: tt.name, InstanceID: "user-" + tt.name, Ext: metav1.Extend{"level": 1}},
+		}
+
+		err := Create(db, &metav1.CreateOptions{DryRun: tt.dryRun}, user)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Create(%v) has an error: want:%v got:%v\n", tt.dryRun, tt.wantErr, err)
+		}
+
+		if !tt.wantErr && (user.ID == 0 || user.CreatedAt.IsZero() || user.ExtShadow != `{"level":1}`) {
+			t.Errorf("Create(%v) has an error: want a would-be object got:%+v\n", tt.dryRun, user.ObjectMeta)
+		}
+
+		var count int
+		db.Model(&testUser{}).Count(&count)
+
+		if count != tt.wantCount {
+			t.Errorf("Create(%v) has an error: want:%d got:%d\n", tt.dryRun, tt.wantCount, count)
+		}
+	}
+
+	// a dry run reports the constraint violations of the real request
+	dup := &testUser{ObjectMeta: metav1.ObjectMeta{Name: "colin", InstanceID: "user-colin"}}
+	if err := Create(db, &metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}, dup); err == nil {
+		t.Errorf("Create has an error: want a unique constraint error got:nil\n")
+	}
+}
@@ -0,0 +1,223 @@
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/neee333ko/component-base/pkg/cli"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+	"github.com/spf13/pflag"
+)
+
+type Feature string
+
+type prerelease string
+
+const (
+	Alpha      = prerelease("ALPHA")
+	Beta       = prerelease("BETA")
+	GA         = prerelease("")
+	Deprecated = prerelease("DEPRECATED")
+)
+
+const flagName = "feature-gates"
+
+// FeatureSpec represents a feature being gated.
+type FeatureSpec struct {
+	Default       bool
+	LockToDefault bool
+	PreRelease    prerelease
+}
+
+// FeatureGate indicates whether a given feature is enabled or not.
+type FeatureGate interface {
+	Enabled(key Feature) bool
+	KnownFeatures() []string
+}
+
+// MutableFeatureGate parses and stores flag gates for known features.
+type MutableFeatureGate interface {
+	FeatureGate
+	pflag.Value
+
+	AddFlag(fs *pflag.FlagSet)
+	AddNamedFlagSet(nfs *cli.NamedFlagSets)
+	SetFromMap(m map[string]bool) error
+	Add(features map[Feature]FeatureSpec) error
+}
+
+var (
+	DefaultMutableFeatureGate MutableFeatureGate = NewFeatureGate()
+	DefaultFeatureGate        FeatureGate        = DefaultMutableFeatureGate
+)
+
+type featureGate struct {
+	mu      sync.RWMutex
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+var _ MutableFeatureGate = &featureGate{}
+
+func NewFeatureGate() *featureGate {
+	return &featureGate{
+		known:   make(map[Feature]FeatureSpec),
+		enabled: make(map[Feature]bool),
+	}
+}
+
+func (f *featureGate) Add(features map[Feature]FeatureSpec) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for name, spec := range features {
+		if existing, ok := f.known[name]; ok {
+			if existing == spec {
+				continue
+			}
+
+			return errors.Errorf("feature gate %q with different spec already exists: %v", name, existing)
+		}
+
+		f.known[name] = spec
+	}
+
+	return nil
+}
+
+func (f *featureGate) Enabled(key Feature) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if v, ok := f.enabled[key]; ok {
+		return v
+	}
+
+	if spec, ok := f.known[key]; ok {
+		return spec.Default
+	}
+
+	panic(fmt.Errorf("feature %q is not registered in FeatureGate", key))
+}
+
+func (f *featureGate) SetFromMap(m map[string]bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	enabled := make(map[Feature]bool, len(f.enabled))
+	for k, v := range f.enabled {
+		enabled[k] = v
+	}
+
+	for k, v := range m {
+		key := Feature(k)
+
+		spec, ok := f.known[key]
+		if !ok {
+			return errors.Errorf("unrecognized feature gate: %s", k)
+		}
+
+		if spec.PreRelease == GA && !v {
+			return errors.Errorf("cannot set feature gate %v to %v, feature is GA", k, v)
+		}
+
+		if spec.LockToDefault && spec.Default != v {
+			return errors.Errorf("cannot set feature gate %v to %v, feature is locked to %v", k, v, spec.Default)
+		}
+
+		if spec.PreRelease == Deprecated {
+			log.Warnf("setting deprecated feature gate %s=%t, it will be removed in a future release\n", k, v)
+		}
+
+		enabled[key] = v
+	}
+
+	f.enabled = enabled
+
+	return nil
+}
+
+// Set parses a string of the form "key1=value1,key2=value2,...".
+func (f *featureGate) Set(value string) error {
+	m := make(map[string]bool)
+
+	for _, s := range strings.Split(value, ",") {
+		if len(s) == 0 {
+			continue
+		}
+
+		arr := strings.SplitN(s, "=", 2)
+		k := strings.TrimSpace(arr[0])
+
+		if len(arr) != 2 {
+			return errors.Errorf("missing bool value for %s", k)
+		}
+
+		v := strings.TrimSpace(arr[1])
+
+		boolValue, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Errorf("invalid value of %s=%s, err: %v", k, v, err)
+		}
+
+		m[k] = boolValue
+	}
+
+	return f.SetFromMap(m)
+}
+
+func (f *featureGate) String() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	pairs := make([]string, 0, len(f.enabled))
+	for k, v := range f.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", k, v))
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (f *featureGate) Type() string {
+	return "mapStringBool"
+}
+
+// KnownFeatures returns a slice of strings describing the known features.
+// GA features are hidden from the list.
+func (f *featureGate) KnownFeatures() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	known := make([]string, 0, len(f.known))
+
+	for k, v := range f.known {
+		if v.PreRelease == GA {
+			continue
+		}
+
+		known = append(known, fmt.Sprintf("%s=true|false (%s - default=%t)", k, v.PreRelease, v.Default))
+	}
+
+	sort.Strings(known)
+
+	return known
+}
+
+func (f *featureGate) AddFlag(fs *pflag.FlagSet) {
+	known := f.KnownFeatures()
+
+	fs.Var(f, flagName, "A set of key=value pairs that describe feature gates for alpha/experimental features. "+
+		"Options are:\n"+strings.Join(known, "\n"))
+}
+
+// AddNamedFlagSet adds the --feature-gates flag to its own section so that it
+// shows up in the help output of nfs.
+func (f *featureGate) AddNamedFlagSet(nfs *cli.NamedFlagSets) {
+	f.AddFlag(nfs.FlagSet("feature gates"))
+}
@@ -0,0 +1,94 @@
+package featuregate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/neee333ko/component-base/pkg/cli"
+)
+
+const (
+	testAlpha      Feature = "TestAlpha"
+	testBeta       Feature = "TestBeta"
+	testGA         Feature = "TestGA"
+	testDeprecated Feature = "TestDeprecated"
+)
+
+func newTestFeatureGate() *featureGate {
+	f := NewFeatureGate()
+	_ = f.Add(map[Feature]FeatureSpec{
+		testAlpha:      {Default: false, PreRelease: Alpha},
+		testBeta:       {Default: true, PreRelease: Beta},
+		testGA:         {Default: true, PreRelease: GA, LockToDefault: true},
+		testDeprecated: {Default: false, PreRelease: Deprecated},
+	})
+
+	return f
+}
+
+func TestFeatureGateSet(t *testing.T) {
+	tests := []struct {
+		arg     string
+		wantErr bool
+		want    map[Feature]bool
+	}{
+		{
+			arg:  "",
+			want: map[Feature]bool{testAlpha: false, testBeta: true, testGA: true},
+		},
+		{
+			arg:  "TestAlpha=true,TestBeta=false",
+			want: map[Feature]bool{testAlpha: true, testBeta: false, testGA: true},
+		},
+		{
+			arg:  "TestGA=true, TestDeprecated=true",
+			want: map[Feature]bool{testGA: true, testDeprecated: true},
+		},
+		{
+			arg:     "TestGA=false",
+			wantErr: true,
+		},
+		{
+			arg:     "Unknown=true",
+			wantErr: true,
+		},
+		{
+			arg:     "TestAlpha",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		f := newTestFeatureGate()
+
+		err := f.Set(tt.arg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q) has an error: want error:%v got:%v\n", tt.arg, tt.wantErr, err)
+		}
+
+		for k, v := range tt.want {
+			if res := f.Enabled(k); res != v {
+				t.Errorf("Set(%q) has an error: %s want:%v got:%v\n", tt.arg, k, v, res)
+			}
+		}
+	}
+}
+
+func TestFeatureGateHelp(t *testing.T) {
+	nfs := cli.NamedFlagSets{}
+	newTestFeatureGate().AddNamedFlagSet(&nfs)
+
+	buf := bytes.NewBuffer(nil)
+	cli.PrintSections(buf, nfs, 0)
+
+	for _, want := range []string{"Feature gates flags:", "--feature-gates", "TestAlpha=true|false (ALPHA - default=false)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("help output misses %q: %s\n", want, buf.String())
+		}
+	}
+
+	if strings.Contains(buf.String(), "TestGA") {
+		t.Errorf("help output should not list GA features: %s\n", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+package healthz
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	"github.com/neee333ko/component-base/pkg/validation"
+	"github.com/neee333ko/errors"
+)
+
+// Checker is a named health check.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c *checkFunc) Name() string { return c.name }
+
+func (c *checkFunc) Check(ctx context.Context) error { return c.fn(ctx) }
+
+// NamedCheck returns a Checker calling fn.
+func NamedCheck(name string, fn func(ctx context.Context) error) Checker {
+	return &checkFunc{name: name, fn: fn}
+}
+
+// PingHealthz always succeeds, it shows that the server answers requests.
+var PingHealthz Checker = NamedCheck("ping", func(ctx context.Context) error { return nil })
+
+// GormCheck pings the database of db.
+func GormCheck(name string, db *gorm.DB) Checker {
+	return NamedCheck(name, func(ctx context.Context) error {
+		return db.DB().PingContext(ctx)
+	})
+}
+
+// DiskSpaceCheck fails when the filesystem of the directory path has less
+// than minFree bytes available.
+func DiskSpaceCheck(name, path string, minFree uint64) (Checker, error) {
+	dir := &struct {
+		Path string `validate:"dir"`
+	}{Path: path}
+
+	if errs := validation.NewValidator(dir).Validate(); len(errs) != 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	return NamedCheck(name, func(ctx context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return errors.Wrapf(err, "stat %s", path)
+		}
+
+		if free < minFree {
+			return errors.Errorf("%s has %d bytes available, want at least %d", path, free, minFree)
+		}
+
+		return nil
+	}), nil
+}
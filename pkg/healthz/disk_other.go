@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package healthz
+
+import "github.com/neee333ko/errors"
+
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("disk space check is not supported on this platform")
+}
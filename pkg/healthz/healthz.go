@@ -0,0 +1,231 @@
+package healthz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/shutdown"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+// DefaultCheckTimeout bounds the duration of a single check.
+const DefaultCheckTimeout = 5 * time.Second
+
+// Registry holds the liveness and readiness checks of a server. /healthz runs
+// all of them, readiness fails once the server is shutting down.
+type Registry struct {
+	mu     sync.RWMutex
+	livez  []Checker
+	readyz []Checker
+
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry returns a registry with the ping liveness and readiness checks
+// and the shutdown readiness check.
+func NewRegistry() *Registry {
+	r := &Registry{}
+	r.livez = []Checker{PingHealthz}
+	r.readyz = []Checker{PingHealthz, NamedCheck("shutdown", func(ctx context.Context) error {
+		if r.shuttingDown.Load() {
+			return errors.New("server is shutting down")
+		}
+
+		return nil
+	})}
+
+	return r
+}
+
+// AddLivezChecks adds checks whose failure means the process must be
+// restarted.
+func (r *Registry) AddLivezChecks(checks ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.livez = append(r.livez, checks...)
+}
+
+// AddReadyzChecks adds checks whose failure means the server must not
+// receive traffic.
+func (r *Registry) AddReadyzChecks(checks ...Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.readyz = append(r.readyz, checks...)
+}
+
+// SetShuttingDown makes the readiness checks fail.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// ShutdownHook fails readiness and waits for delay, or ctx, so that load
+// balancers stop routing traffic before the server is shut down. Register it
+// with shutdown.OrderReadiness.
+func (r *Registry) ShutdownHook(delay time.Duration) shutdown.HookFunc {
+	return func(ctx context.Context) error {
+		r.SetShuttingDown()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+
+		return nil
+	}
+}
+
+// Install registers /livez, /readyz and /healthz on router, plus one path per
+// check, e.g. /readyz/db.
+func (r *Registry) Install(router gin.IRoutes) {
+	for _, endpoint := range []struct {
+		name   string
+		checks func() []Checker
+	}{
+		{name: "livez", checks: r.livezChecks},
+		{name: "readyz", checks: r.readyzChecks},
+		{name: "healthz", checks: r.healthzChecks},
+	} {
+		router.GET("/"+endpoint.name, handler(endpoint.name, endpoint.checks))
+		router.GET("/"+endpoint.name+"/:check", checkHandler(endpoint.checks))
+	}
+}
+
+func (r *Registry) livezChecks() []Checker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Checker{}, r.livez...)
+}
+
+func (r *Registry) readyzChecks() []Checker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Checker{}, r.readyz...)
+}
+
+// healthzChecks returns the liveness and readiness checks without duplicates.
+func (r *Registry) healthzChecks() []Checker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	checks := make([]Checker, 0, len(r.livez)+len(r.readyz))
+
+	for _, check := range append(append([]Checker{}, r.livez...), r.readyz...) {
+		if !seen[check.Name()] {
+			seen[check.Name()] = true
+			checks = append(checks, check)
+		}
+	}
+
+	return checks
+}
+
+// handler runs the checks that are not listed in ?exclude=, the output lists
+// every check with ?verbose or when one of them fails.
+func handler(name string, checks func() []Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		excluded := make(map[string]bool)
+
+		for _, v := range c.QueryArray("exclude") {
+			for _, e := range strings.Split(v, ",") {
+				if e = strings.TrimSpace(e); e != "" {
+					excluded[e] = true
+				}
+			}
+		}
+
+		var output bytes.Buffer
+
+		failed := make([]string, 0)
+
+		for _, check := range checks() {
+			if excluded[check.Name()] {
+				delete(excluded, check.Name())
+				fmt.Fprintf(&output, "[+]%s excluded: ok\n", check.Name())
+
+				continue
+			}
+
+			if err := runCheck(c, check); err != nil {
+				log.L(c).Warnf("%s check %s failed: %s\n", name, check.Name(), err.Error())
+				// errors may leak hosts or paths, they are only logged
+				fmt.Fprintf(&output, "[-]%s failed: reason withheld\n", check.Name())
+
+				failed = append(failed, check.Name())
+
+				continue
+			}
+
+			fmt.Fprintf(&output, "[+]%s ok\n", check.Name())
+		}
+
+		if len(excluded) > 0 {
+			names := make([]string, 0, len(excluded))
+			for e := range excluded {
+				names = append(names, fmt.Sprintf("%q", e))
+			}
+
+			sort.Strings(names)
+			fmt.Fprintf(&output, "warn: some health checks cannot be excluded: no matches for %s\n", strings.Join(names, ","))
+		}
+
+		if len(failed) > 0 {
+			fmt.Fprintf(&output, "%s check failed\n", name)
+			c.Data(http.StatusInternalServerError, "text/plain; charset=utf-8", output.Bytes())
+
+			return
+		}
+
+		if _, verbose := c.GetQuery("verbose"); !verbose {
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte("ok"))
+
+			return
+		}
+
+		fmt.Fprintf(&output, "%s check passed\n", name)
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", output.Bytes())
+	}
+}
+
+func checkHandler(checks func() []Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, check := range checks() {
+			if check.Name() != c.Param("check") {
+				continue
+			}
+
+			if err := runCheck(c, check); err != nil {
+				log.L(c).Warnf("check %s failed: %s\n", check.Name(), err.Error())
+				c.Data(http.StatusInternalServerError, "text/plain; charset=utf-8", []byte("internal server error: "+check.Name()+" check failed"))
+
+				return
+			}
+
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte("ok"))
+
+			return
+		}
+
+		c.Data(http.StatusNotFound, "text/plain; charset=utf-8", []byte("no such check: "+c.Param("check")))
+	}
+}
+
+func runCheck(c *gin.Context, check Checker) error {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), DefaultCheckTimeout)
+	defer cancel()
+
+	return check.Check(ctx)
+}
@@ -0,0 +1,114 @@
+package healthz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/errors"
+)
+
+func TestRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dbDown := true
+	disk, err := DiskSpaceCheck("disk", os.TempDir(), 1)
+	if err != nil {
+		t.Fatalf("DiskSpaceCheck has an error: %v\n", err)
+	}
+
+	r := NewRegistry()
+	r.AddLivezChecks(disk)
+	r.AddReadyzChecks(NamedCheck("db", func(ctx context.Context) error {
+		if dbDown {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	}))
+
+	engine := gin.New()
+	r.Install(engine)
+
+	tests := []struct {
+		target     string
+		dbDown     bool
+		shutdown   bool
+		wantStatus int
+		want       []string
+	}{
+		{target: "/livez", dbDown: true, wantStatus: http.StatusOK, want: []string{"ok"}},
+		{target: "/livez?verbose", wantStatus: http.StatusOK, want: []string{"[+]ping ok", "[+]disk ok", "livez check passed"}},
+		{target: "/readyz", dbDown: true, wantStatus: http.StatusInternalServerError, want: []string{"[-]db failed: reason withheld", "readyz check failed"}},
+		{target: "/readyz?exclude=db&exclude=nope", dbDown: true, wantStatus: http.StatusOK, want: []string{"ok"}},
+		{
+			target: "/readyz?verbose&exclude=db,nope", dbDown: true, wantStatus: http.StatusOK,
+			want: []string{"[+]db excluded: ok", `no matches for "nope"`},
+		},
+		{target: "/healthz?verbose", wantStatus: http.StatusOK, want: []string{"[+]ping ok", "[+]disk ok", "[+]shutdown ok", "[+]db ok"}},
+		{target: "/readyz/db", dbDown: true, wantStatus: http.StatusInternalServerError},
+		{target: "/livez/db", wantStatus: http.StatusNotFound},
+		{target: "/readyz", shutdown: true, wantStatus: http.StatusInternalServerError, want: []string{"[-]shutdown failed"}},
+		{target: "/livez", shutdown: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		dbDown = tt.dbDown
+		if tt.shutdown {
+			_ = r.ShutdownHook(0)(context.Background())
+		}
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("GET %s has an error: want:%d got:%d\n%s\n", tt.target, tt.wantStatus, w.Code, w.Body.String())
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("GET %s misses %q:\n%s\n", tt.target, want, w.Body.String())
+			}
+		}
+
+		if strings.Contains(w.Body.String(), "connection refused") {
+			t.Errorf("GET %s has an error: check errors must not be exposed:\n%s\n", tt.target, w.Body.String())
+		}
+
+		if strings.Count(w.Body.String(), "[+]ping ok") > 1 {
+			t.Errorf("GET %s has an error: duplicated checks:\n%s\n", tt.target, w.Body.String())
+		}
+	}
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	tests := []struct {
+		path      string
+		minFree   uint64
+		wantErr   bool
+		wantCheck bool
+	}{
+		{path: os.TempDir(), minFree: 1},
+		{path: os.TempDir(), minFree: 1 << 62, wantCheck: true},
+		{path: "non-existing dir", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		check, err := DiskSpaceCheck("disk", tt.path, tt.minFree)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("DiskSpaceCheck(%s) has an error: want:%v got:%v\n", tt.path, tt.wantErr, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if err := check.Check(context.Background()); (err != nil) != tt.wantCheck {
+			t.Errorf("Check(%s, %d) has an error: want:%v got:%v\n", tt.path, tt.minFree, tt.wantCheck, err)
+		}
+	}
+}
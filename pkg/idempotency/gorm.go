@@ -0,0 +1,94 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/neee333ko/component-base/pkg/json"
+)
+
+type gormRecord struct {
+	Key         string    `gorm:"primary_key;column:idempotency_key;type:varchar(64)"`
+	RequestHash string    `gorm:"column:request_hash;type:varchar(64);not null"`
+	Completed   bool      `gorm:"column:completed;not null"`
+	Status      int       `gorm:"column:status"`
+	Header      string    `gorm:"column:header;type:text"`
+	Body        []byte    `gorm:"column:body"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;index"`
+}
+
+func (gormRecord) TableName() string {
+	return "idempotency_records"
+}
+
+func (r *gormRecord) record() *Record {
+	header := http.Header{}
+	_ = json.Unmarshal([]byte(r.Header), &header)
+
+	return &Record{
+		Key:         r.Key,
+		RequestHash: r.RequestHash,
+		Completed:   r.Completed,
+		Status:      r.Status,
+		Header:      header,
+		Body:        r.Body,
+		ExpiresAt:   r.ExpiresAt,
+	}
+}
+
+// GormStore keeps records in the idempotency_records table, the primary key
+// makes Lock safe across replicas.
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+var _ Store = &GormStore{}
+
+// AutoMigrate creates the idempotency_records table.
+func (s *GormStore) AutoMigrate() error {
+	return s.db.AutoMigrate(&gormRecord{}).Error
+}
+
+func (s *GormStore) Lock(ctx context.Context, key, requestHash string, timeout time.Duration) (*Record, bool, error) {
+	now := time.Now()
+
+	if err := s.db.Where("idempotency_key = ? AND expires_at < ?", key, now).Delete(&gormRecord{}).Error; err != nil {
+		return nil, false, err
+	}
+
+	if err := s.db.Create(&gormRecord{Key: key, RequestHash: requestHash, ExpiresAt: now.Add(timeout)}).Error; err == nil {
+		return nil, true, nil
+	}
+
+	var existing gormRecord
+	if err := s.db.Where("idempotency_key = ?", key).First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	return existing.record(), false, nil
+}
+
+func (s *GormStore) Complete(ctx context.Context, record *Record) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(&gormRecord{}).Where("idempotency_key = ?", record.Key).Updates(map[string]interface{}{
+		"completed":  true,
+		"status":     record.Status,
+		"header":     string(header),
+		"body":       record.Body,
+		"expires_at": record.ExpiresAt,
+	}).Error
+}
+
+func (s *GormStore) Release(ctx context.Context, key string) error {
+	return s.db.Where("idempotency_key = ?", key).Delete(&gormRecord{}).Error
+}
@@ -0,0 +1,241 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/core"
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+const (
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses replayed from the store.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	DefaultTTL         = 24 * time.Hour
+	DefaultLockTimeout = 5 * time.Minute
+	DefaultMaxBodySize = 10 << 20
+	maxKeyLength       = 255
+)
+
+// Config configures the idempotency Middleware, zero values fall back to the
+// defaults.
+type Config struct {
+	// TTL is how long a response is replayed for retries.
+	TTL time.Duration
+	// LockTimeout is how long a key is reserved for the request in flight. A
+	// key whose request never completed, e.g. on a crashed replica, is taken
+	// over once it expires, so it should exceed the longest request.
+	LockTimeout time.Duration
+	// MaxBodySize limits the request bodies buffered for hashing, larger
+	// bodies are rejected with ErrBind.
+	MaxBodySize int64
+}
+
+// Middleware makes POST, PUT, PATCH and DELETE requests carrying an
+// Idempotency-Key header idempotent. The first response of a key, scoped to
+// the route and the principal set by the auth middleware, is stored for
+// config.TTL and replayed for retries. Retries sent while the first request
+// is in flight are rejected with ErrIdempotencyKeyInUse, reusing a key with
+// another body with ErrIdempotencyKeyMismatch. Server errors, 409 and 429
+// responses are not stored so that the request can be retried, per-request
+// headers such as X-Request-ID are not replayed.
+func Middleware(store Store, config Config) gin.HandlerFunc {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = DefaultLockTimeout
+	}
+
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderKey)
+		if key == "" || !unsafeMethod(c.Request.Method) {
+			c.Next()
+
+			return
+		}
+
+		if len(key) > maxKeyLength {
+			core.WriteResponse(c, errors.WithCode(core.ErrBind, "Idempotency-Key must not be longer than 255 characters"), nil)
+			c.Abort()
+
+			return
+		}
+
+		// the body is hashed while it is buffered for the handler
+		var body bytes.Buffer
+
+		h := sha256.New()
+		writeParts(h, c.Request.Method, c.Request.URL.RequestURI())
+		if _, err := io.Copy(h, io.TeeReader(http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxBodySize), &body)); err != nil {
+			msg := err.Error()
+
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				msg = fmt.Sprintf("request body must not be larger than %d bytes", tooLarge.Limit)
+			}
+
+			core.WriteResponse(c, errors.WrapC(err, core.ErrBind, msg), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Request.Body = io.NopCloser(&body)
+
+		storeKey := hash(c.GetString(log.KeyUsername), route(c), key)
+		requestHash := hex.EncodeToString(h.Sum(nil))
+
+		record, locked, err := store.Lock(c, storeKey, requestHash, config.LockTimeout)
+		if err != nil {
+			core.WriteResponse(c, errors.WrapC(err, core.ErrInternal, err.Error()), nil)
+			c.Abort()
+
+			return
+		}
+
+		if !locked {
+			replay(c, record, requestHash)
+			c.Abort()
+
+			return
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		completed := false
+
+		defer func() {
+			if !completed {
+				if err := store.Release(c, storeKey); err != nil {
+					log.L(c).Errorf("release idempotency key failed: %s\n", err.Error())
+				}
+			}
+		}()
+
+		c.Next()
+
+		if !storable(w.Status()) {
+			return
+		}
+
+		header := w.Header().Clone()
+		for _, k := range perRequestHeaders {
+			header.Del(k)
+		}
+
+		if err := store.Complete(c, &Record{
+			Key:         storeKey,
+			RequestHash: requestHash,
+			Status:      w.Status(),
+			Header:      header,
+			Body:        w.body.Bytes(),
+			ExpiresAt:   time.Now().Add(config.TTL),
+		}); err != nil {
+			log.L(c).Errorf("store idempotent response failed: %s\n", err.Error())
+
+			return
+		}
+
+		completed = true
+	}
+}
+
+func replay(c *gin.Context, record *Record, requestHash string) {
+	switch {
+	case record.RequestHash != requestHash:
+		core.WriteResponse(c, errors.WithCode(core.ErrIdempotencyKeyMismatch, "Idempotency-Key was used for a different request"), nil)
+	case !record.Completed:
+		core.WriteResponse(c, errors.WithCode(core.ErrIdempotencyKeyInUse, "a request with the same Idempotency-Key is being processed"), nil)
+	default:
+		for k, values := range record.Header {
+			c.Writer.Header()[k] = values
+		}
+
+		c.Header(HeaderReplayed, "true")
+		c.Status(record.Status)
+		_, _ = c.Writer.Write(record.Body)
+	}
+}
+
+// perRequestHeaders describe the response to one request, a replay keeps the
+// values set for the retry.
+var perRequestHeaders = []string{
+	core.XRequestIDKey,
+	"Date",
+	core.RateLimitLimitHeader,
+	core.RateLimitRemainingHeader,
+	core.RateLimitResetHeader,
+	core.RetryAfterHeader,
+}
+
+// storable reports whether a response is final. Server errors, conflicts and
+// rate limited requests may succeed when retried.
+func storable(status int) bool {
+	return status < http.StatusInternalServerError && status != http.StatusConflict && status != http.StatusTooManyRequests
+}
+
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+func route(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return c.Request.Method + " " + path
+	}
+
+	return c.Request.Method + " " + c.Request.URL.Path
+}
+
+func hash(parts ...string) string {
+	h := sha256.New()
+	writeParts(h, parts...)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeParts writes parts to w, each terminated by a NUL byte.
+func writeParts(w io.Writer, parts ...string) {
+	for _, p := range parts {
+		_, _ = w.Write([]byte(p))
+		_, _ = w.Write([]byte{0})
+	}
+}
+
+// recorder keeps a copy of the response body.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+
+	return r.ResponseWriter.WriteString(s)
+}
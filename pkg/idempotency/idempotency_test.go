@@ -0,0 +1,216 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/neee333ko/component-base/pkg/core"
+	"github.com/neee333ko/log"
+)
+
+func newTestEngine(store Store, created *int, block func()) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set(log.KeyUsername, c.GetHeader("X-User"))
+	}, core.RequestID(), Middleware(store, Config{TTL: time.Hour, MaxBodySize: 32}))
+	engine.POST("/v1/users", func(c *gin.Context) {
+		if block != nil {
+			block()
+		}
+
+		if status, _ := strconv.Atoi(c.GetHeader("X-Status")); status != 0 {
+			c.Status(status)
+
+			return
+		}
+
+		*created++
+		c.Header("Location", "/v1/users/colin")
+		c.String(http.StatusCreated, "created %d", *created)
+	})
+
+	return engine
+}
+
+func send(engine *gin.Engine, key, user, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User", user)
+
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	created := 0
+	engine := newTestEngine(NewMemoryStore(), &created, nil)
+
+	tests := []struct {
+		key        string
+		user       string
+		body       string
+		status     string
+		wantStatus int
+		wantBody   string
+		replayed   bool
+	}{
+		{key: "k1", user: "colin", body: `{"name":"colin"}`, wantStatus: http.StatusCreated, wantBody: "created 1"},
+		{key: "k1", user: "colin", body: `{"name":"colin"}`, wantStatus: http.StatusCreated, wantBody: "created 1", replayed: true},
+		{key: "k1", user: "colin", body: `{"name":"lex"}`, wantStatus: http.StatusUnprocessableEntity},
+		{key: "k1", user: "lex", body: `{"name":"colin"}`, wantStatus: http.StatusCreated, wantBody: "created 2"},
+		{key: "", user: "colin", body: `{"name":"colin"}`, wantStatus: http.StatusCreated, wantBody: "created 3"},
+		{key: "k2", user: "colin", body: `{}`, status: "503", wantStatus: http.StatusServiceUnavailable},
+		{key: "k2", user: "colin", body: `{}`, status: "503", wantStatus: http.StatusServiceUnavailable},
+		{key: "k3", user: "colin", body: `{}`, status: "429", wantStatus: http.StatusTooManyRequests},
+		{key: "k3", user: "colin", body: `{}`, status: "409", wantStatus: http.StatusConflict},
+		{key: "k3", user: "colin", body: `{}`, wantStatus: http.StatusCreated, wantBody: "created 4"},
+		{key: "k3", user: "colin", body: `{}`, wantStatus: http.StatusCreated, wantBody: "created 4", replayed: true},
+		{key: strings.Repeat("k", 256), user: "colin", body: `{}`, wantStatus: http.StatusBadRequest},
+		{key: "k4", user: "colin", body: strings.Repeat("x", 33), wantStatus: http.StatusBadRequest},
+		{key: "k4", user: "colin", body: strings.Repeat("x", 32), status: "200", wantStatus: http.StatusOK},
+	}
+
+	for i, tt := range tests {
+		rid := "request-" + strconv.Itoa(i)
+		w := send(engine, tt.key, tt.user, tt.body, map[string]string{"X-Status": tt.status, core.XRequestIDKey: rid})
+
+		if w.Code != tt.wantStatus || (tt.wantBody != "" && w.Body.String() != tt.wantBody) {
+			t.Errorf("POST #%d (%s, %s) has an error: want:%d,%s got:%d,%s\n", i, tt.user, tt.body, tt.wantStatus, tt.wantBody, w.Code, w.Body.String())
+		}
+
+		if replayed := w.Header().Get(HeaderReplayed) == "true"; replayed != tt.replayed || (replayed && w.Header().Get("Location") == "") {
+			t.Errorf("POST #%d (%s, %s) replay has an error: want:%v got:%v\n", i, tt.user, tt.body, tt.replayed, w.Header())
+		}
+
+		if got := w.Header().Values(core.XRequestIDKey); len(got) != 1 || got[0] != rid {
+			t.Errorf("POST #%d request ID has an error: want:%s got:%v\n", i, rid, got)
+		}
+	}
+}
+
+func TestMiddlewareInFlight(t *testing.T) {
+	created := 0
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	var once sync.Once
+
+	engine := newTestEngine(NewMemoryStore(), &created, func() {
+		once.Do(func() {
+			close(entered)
+			<-release
+		})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send(engine, "k1", "colin", `{}`, nil) }()
+
+	<-entered
+
+	w := send(engine, "k1", "colin", `{}`, nil)
+
+	close(release)
+
+	if first := <-done; first.Code != http.StatusCreated || w.Code != http.StatusConflict {
+		t.Errorf("in-flight retry has an error: want:%d,%d got:%d,%d\n", http.StatusCreated, http.StatusConflict, first.Code, w.Code)
+	}
+
+	if w := send(engine, "k1", "colin", `{}`, nil); w.Code != http.StatusCreated || created != 1 {
+		t.Errorf("retry after completion has an error: want:%d,1 got:%d,%d\n", http.StatusCreated, w.Code, created)
+	}
+}
+
+func TestGormStore(t *testing.T) {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		// go-sqlite3 needs cgo
+		t.Skipf("open database has an error: %v\n", err)
+	}
+	defer db.Close()
+
+	store := NewGormStore(db)
+	if err := store.AutoMigrate(); err != nil {
+		t.Fatalf("AutoMigrate has an error: %v\n", err)
+	}
+
+	testStore(t, store)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	if _, locked, err := store.Lock(ctx, "k1", "h1", time.Hour); !locked || err != nil {
+		t.Fatalf("Lock has an error: want:true got:%v,%v\n", locked, err)
+	}
+
+	if r, locked, err := store.Lock(ctx, "k1", "h1", time.Hour); locked || err != nil || r.Completed {
+		t.Errorf("Lock in flight has an error: got:%+v,%v,%v\n", r, locked, err)
+	}
+
+	header := http.Header{"Location": []string{"/v1/users/colin"}}
+	if err := store.Complete(ctx, &Record{Key: "k1", RequestHash: "h1", Status: http.StatusCreated, Header: header, Body: []byte("created"), ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Complete has an error: %v\n", err)
+	}
+
+	r, locked, err := store.Lock(ctx, "k1", "h1", time.Hour)
+	if locked || err != nil || !r.Completed || r.Status != http.StatusCreated || string(r.Body) != "created" || r.Header.Get("Location") != "/v1/users/colin" {
+		t.Errorf("Lock completed has an error: got:%+v,%v,%v\n", r, locked, err)
+	}
+
+	if _, locked, _ := store.Lock(ctx, "k2", "h2", -time.Second); !locked {
+		t.Errorf("Lock has an error: want:true got:false\n")
+	}
+
+	// the lock of a request that never completed is taken over
+	if _, locked, _ := store.Lock(ctx, "k2", "h2", time.Hour); !locked {
+		t.Errorf("Lock expired has an error: want:true got:false\n")
+	}
+
+	// Complete keeps the response past the lock timeout
+	if _, locked, _ := store.Lock(ctx, "k3", "h3", time.Millisecond); !locked {
+		t.Errorf("Lock has an error: want:true got:false\n")
+	}
+
+	if err := store.Complete(ctx, &Record{Key: "k3", RequestHash: "h3", Status: http.StatusOK, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Complete has an error: %v\n", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if r, locked, err := store.Lock(ctx, "k3", "h3", time.Hour); locked || err != nil || !r.Completed {
+		t.Errorf("Lock completed after lock timeout has an error: got:%+v,%v,%v\n", r, locked, err)
+	}
+
+	if err := store.Release(ctx, "k1"); err != nil {
+		t.Fatalf("Release has an error: %v\n", err)
+	}
+
+	if _, locked, _ := store.Lock(ctx, "k1", "h3", time.Hour); !locked {
+		t.Errorf("Lock released has an error: want:true got:false\n")
+	}
+}
@@ -0,0 +1,92 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the stored outcome of the first request made with a key.
+type Record struct {
+	Key         string
+	RequestHash string
+	// Completed is false while the first request is being processed.
+	Completed bool
+	Status    int
+	Header    http.Header
+	Body      []byte
+	ExpiresAt time.Time
+}
+
+// Store persists idempotency records.
+type Store interface {
+	// Lock reserves key for a request with hash requestHash until timeout
+	// expires, an expired key is taken over. When key is already taken it
+	// returns the existing record and false.
+	Lock(ctx context.Context, key, requestHash string, timeout time.Duration) (*Record, bool, error)
+	// Complete stores the response of the request holding key until
+	// record.ExpiresAt.
+	Complete(ctx context.Context, record *Record) error
+	// Release drops key so that the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryStore keeps records in memory, expired records are purged lazily.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+	lastGC  time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+var _ Store = &MemoryStore{}
+
+func (s *MemoryStore) Lock(ctx context.Context, key, requestHash string, timeout time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastGC) > time.Minute {
+		for k, r := range s.records {
+			if now.After(r.ExpiresAt) {
+				delete(s.records, k)
+			}
+		}
+
+		s.lastGC = now
+	}
+
+	if r, ok := s.records[key]; ok && !now.After(r.ExpiresAt) {
+		cp := *r
+
+		return &cp, false, nil
+	}
+
+	s.records[key] = &Record{Key: key, RequestHash: requestHash, ExpiresAt: now.Add(timeout)}
+
+	return nil, true, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := *record
+	cp.Completed = true
+	s.records[record.Key] = &cp
+
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
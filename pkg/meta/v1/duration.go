@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/neee333ko/component-base/pkg/json"
+)
+
+// Duration is a time.Duration written as a string in JSON and YAML, e.g.
+// "1m30s". Numbers are read as nanoseconds.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Duration.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if n, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		d.Duration = time.Duration(n)
+
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	d.Duration = parsed
+
+	return nil
+}
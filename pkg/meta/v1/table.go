@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/neee333ko/component-base/pkg/util/duration"
+)
+
+// Table is a tabular representation of a list of objects, requested with
+// `Accept: application/json;as=Table`.
+type Table struct {
+	TypeMeta `json:",inline"`
+	ListMeta `json:",inline"`
+
+	// ColumnDefinitions is empty when TableOptions.NoHeaders is set.
+	ColumnDefinitions []TableColumnDefinition `json:"columnDefinitions,omitempty"`
+	Rows              []TableRow              `json:"rows"`
+}
+
+// TableColumnDefinition describes a column of a Table.
+type TableColumnDefinition struct {
+	Name string `json:"name"`
+	// Type is an OpenAPI type, e.g. string, integer, number or boolean.
+	Type   string `json:"type"`
+	Format string `json:"format,omitempty"`
+	// Description is a human readable description of the column.
+	Description string `json:"description,omitempty"`
+	// Priority 0 columns are always shown, higher priorities only in wide
+	// output.
+	Priority int32 `json:"priority"`
+}
+
+// TableRow holds the cells of one object, in the order of the column
+// definitions.
+type TableRow struct {
+	Cells []interface{} `json:"cells"`
+	// Object is the ObjectMeta of the row object, if it has one.
+	Object *ObjectMeta `json:"object,omitempty"`
+}
+
+// TableColumn is a column computed for objects of a registered kind.
+type TableColumn struct {
+	TableColumnDefinition
+	Value func(obj interface{}) interface{}
+}
+
+var (
+	tableColumnsMu sync.RWMutex
+	tableColumns   = make(map[string][]TableColumn)
+)
+
+// RegisterTableColumns registers the columns added between the default name,
+// instanceID and age columns for objects of kind.
+func RegisterTableColumns(kind string, cols ...TableColumn) {
+	tableColumnsMu.Lock()
+	defer tableColumnsMu.Unlock()
+
+	tableColumns[kind] = append(tableColumns[kind], cols...)
+}
+
+var (
+	nameTableColumn = TableColumn{
+		TableColumnDefinition: TableColumnDefinition{Name: "Name", Type: "string", Format: "name", Description: "Name of the object."},
+		Value: func(obj interface{}) interface{} {
+			if meta := objectMetaOf(obj); meta != nil {
+				return meta.Name
+			}
+
+			return ""
+		},
+	}
+	instanceIDTableColumn = TableColumn{
+		TableColumnDefinition: TableColumnDefinition{Name: "InstanceID", Type: "string", Description: "Unique instance ID of the object.", Priority: 1},
+		Value: func(obj interface{}) interface{} {
+			if meta := objectMetaOf(obj); meta != nil {
+				return meta.InstanceID
+			}
+
+			return ""
+		},
+	}
+	ageTableColumn = TableColumn{
+		TableColumnDefinition: TableColumnDefinition{Name: "Age", Type: "string", Format: "date", Description: "Time since the object was created."},
+		Value: func(obj interface{}) interface{} {
+			meta := objectMetaOf(obj)
+			if meta == nil || meta.CreatedAt.IsZero() {
+				return "<unknown>"
+			}
+
+			return duration.HumanDuration(time.Since(meta.CreatedAt))
+		},
+	}
+)
+
+// TableColumns returns the columns of a Table of kind objects.
+func TableColumns(kind string) []TableColumn {
+	tableColumnsMu.RLock()
+	defer tableColumnsMu.RUnlock()
+
+	cols := append([]TableColumn{nameTableColumn, instanceIDTableColumn}, tableColumns[kind]...)
+
+	return append(cols, ageTableColumn)
+}
+
+// ConvertToTable converts a list (a struct with an Items field), a slice or
+// a single object into a Table.
+func ConvertToTable(obj interface{}, opts *TableOptions) *Table {
+	items := tableItems(obj)
+
+	table := &Table{
+		TypeMeta: TypeMeta{Type: "Table", ApiVersion: "meta/v1"},
+		ListMeta: ListMeta{TotalCount: int64(len(items))},
+		Rows:     make([]TableRow, 0, len(items)),
+	}
+
+	if l, ok := obj.(List); ok {
+		table.TotalCount = l.GetTotalCount()
+	}
+
+	kind := ""
+	if len(items) > 0 {
+		kind = kindOf(items[0])
+	}
+
+	cols := TableColumns(kind)
+
+	if opts == nil || !opts.NoHeaders {
+		for _, col := range cols {
+			table.ColumnDefinitions = append(table.ColumnDefinitions, col.TableColumnDefinition)
+		}
+	}
+
+	for _, item := range items {
+		row := TableRow{Cells: make([]interface{}, 0, len(cols)), Object: objectMetaOf(item)}
+		for _, col := range cols {
+			row.Cells = append(row.Cells, col.Value(item))
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+func objectMetaOf(obj interface{}) *ObjectMeta {
+	accessor, ok := obj.(ObjectAccessor)
+	if !ok {
+		return nil
+	}
+
+	meta, _ := accessor.GetObject().(*ObjectMeta)
+
+	return meta
+}
+
+// kindOf returns the kind set in TypeMeta, or the go type name of obj.
+func kindOf(obj interface{}) string {
+	if t, ok := obj.(Type); ok && t.GetKind() != "" {
+		return t.GetKind()
+	}
+
+	t := reflect.TypeOf(obj)
+	if t == nil {
+		return ""
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// tableItems returns the elements of the Items field of a list, the elements
+// of a slice or obj itself.
+func tableItems(obj interface{}) []interface{} {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Struct {
+		if items := v.FieldByName("Items"); items.IsValid() && items.Kind() == reflect.Slice {
+			v = items
+		}
+	}
+
+	if v.Kind() != reflect.Slice {
+		return []interface{}{obj}
+	}
+
+	items := make([]interface{}, 0, v.Len())
+
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		if item.Kind() != reflect.Ptr && item.CanAddr() {
+			item = item.Addr()
+		}
+
+		items = append(items, item.Interface())
+	}
+
+	return items
+}
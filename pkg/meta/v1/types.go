@@ -29,6 +29,10 @@ func (ext Extend) Merge(extendShadow string) Extend {
 	return ext
 }
 
+// DryRunAll is the only supported dryRun value, all stages are processed
+// and nothing is persisted.
+const DryRunAll = "All"
+
 type TypeMeta struct {
 	Type       string `json:"type,omitempty"`
 	ApiVersion string `json:"apiVersion,omitempty"`
@@ -70,7 +74,7 @@ type ListOptions struct {
 	TypeMeta       `json:",inline"`
 	LabelSelector  string `json:"labelSelector,omitempty" form:"labelSelector"`
 	FieldSelector  string `json:"fieldSelector,omitempty" form:"fieldSelector"`
-	TimeoutSeconds int64  `json:"timeoutSeconds,omitempty"`
+	TimeoutSeconds int64  `json:"timeoutSeconds,omitempty" form:"timeoutSeconds"`
 	Limit          int64  `json:"limit,omitempty" form:"limit"`
 	Offset         int64  `json:"offset,omitempty" form:"offset"`
 }
@@ -109,7 +113,7 @@ type CreateOptions struct {
 	// request. Valid values are:
 	// - All: all dry run stages will be processed
 	// +optional
-	DryRun []string `json:"dryRun,omitempty"`
+	DryRun []string `json:"dryRun,omitempty" form:"dryRun"`
 }
 
 // PatchOptions may be provided when patching an API object.
@@ -123,7 +127,7 @@ type PatchOptions struct {
 	// request. Valid values are:
 	// - All: all dry run stages will be processed
 	// +optional
-	DryRun []string `json:"dryRun,omitempty"`
+	DryRun []string `json:"dryRun,omitempty" form:"dryRun"`
 
 	// Force is going to "force" Apply requests. It means user will
 	// re-acquire conflicting fields owned by other people. Force
@@ -143,7 +147,7 @@ type UpdateOptions struct {
 	// request. Valid values are:
 	// - All: all dry run stages will be processed
 	// +optional
-	DryRun []string `json:"dryRun,omitempty"`
+	DryRun []string `json:"dryRun,omitempty" form:"dryRun"`
 }
 
 // AuthorizeOptions may be provided when authorize an API object.
@@ -0,0 +1,33 @@
+package validation
+
+import (
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+)
+
+var supportedDryRunValues = []string{metav1.DryRunAll}
+
+// ValidateDryRun rejects every dryRun value but "All".
+func ValidateDryRun(fldPath *field.Path, dryRun []string) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, v := range dryRun {
+		if v != metav1.DryRunAll {
+			allErrs = append(allErrs, field.NotSupport(fldPath.Index(i), v, supportedDryRunValues))
+		}
+	}
+
+	return allErrs
+}
+
+func ValidateCreateOptions(opts *metav1.CreateOptions) field.ErrorList {
+	return ValidateDryRun(field.NewPath("dryRun"), opts.DryRun)
+}
+
+func ValidateUpdateOptions(opts *metav1.UpdateOptions) field.ErrorList {
+	return ValidateDryRun(field.NewPath("dryRun"), opts.DryRun)
+}
+
+func ValidatePatchOptions(opts *metav1.PatchOptions) field.ErrorList {
+	return ValidateDryRun(field.NewPath("dryRun"), opts.DryRun)
+}
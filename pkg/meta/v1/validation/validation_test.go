@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"testing"
+
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+)
+
+func TestValidateDryRun(t *testing.T) {
+	tests := []struct {
+		dryRun []string
+		want   []string
+	}{
+		{dryRun: nil},
+		{dryRun: []string{metav1.DryRunAll}},
+		{dryRun: []string{"All", "Some"}, want: []string{"dryRun[1]"}},
+		{dryRun: []string{"all"}, want: []string{"dryRun[0]"}},
+	}
+
+	for _, tt := range tests {
+		errs := ValidateCreateOptions(&metav1.CreateOptions{DryRun: tt.dryRun})
+
+		if len(errs) != len(tt.want) {
+			t.Errorf("ValidateDryRun(%v) has an error: want:%v got:%v\n", tt.dryRun, tt.want, errs)
+
+			continue
+		}
+
+		for i, err := range errs {
+			if err.Field() != tt.want[i] || err.Type() != field.ErrorNotSupport {
+				t.Errorf("ValidateDryRun(%v) has an error: want:%s got:%v\n", tt.dryRun, tt.want[i], err)
+			}
+		}
+	}
+}
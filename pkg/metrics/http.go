@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/core"
+	"github.com/neee333ko/component-base/pkg/version"
+)
+
+// ContentType is the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	requestsTotal = NewCounterVec("http_requests_total",
+		"Number of HTTP requests by route, method, status and the code of the error written by core.WriteResponse.",
+		"route", "method", "status", "code")
+	requestDuration = NewHistogramVec("http_request_duration_seconds",
+		"Latency of HTTP requests by route and method.", nil, "route", "method")
+	requestsInFlight = NewGaugeVec("http_requests_in_flight",
+		"Number of HTTP requests being served by route and method.", "route", "method")
+	buildInfo = NewGaugeVec("build_info",
+		"Build information of the binary, always 1.",
+		"git_version", "git_commit", "git_tree_state", "build_date", "go_version", "platform")
+)
+
+func init() {
+	info := version.Get()
+	buildInfo.Set(1, info.GitVersion, info.GitCommit, info.GitTreeState, info.BuildDate, info.GoVersion, info.Platform)
+
+	DefaultRegistry.MustRegister(requestsTotal, requestDuration, requestsInFlight, buildInfo)
+}
+
+// Middleware records the request count, latency and in-flight requests of
+// every route into DefaultRegistry. Requests matching no route are recorded
+// with the route "<unmatched>".
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "<unmatched>"
+		}
+
+		method := methodLabel(c.Request.Method)
+		start := time.Now()
+
+		requestsInFlight.Inc(route, method)
+		defer requestsInFlight.Dec(route, method)
+
+		c.Next()
+
+		code := ""
+		if v, ok := c.Get(core.ErrorCodeKey); ok {
+			code = strconv.Itoa(v.(int))
+		}
+
+		requestsTotal.Inc(route, method, strconv.Itoa(c.Writer.Status()), code)
+		requestDuration.Observe(time.Since(start).Seconds(), route, method)
+	}
+}
+
+// methodLabel keeps the label values bounded, unknown methods are recorded as
+// "other".
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return "other"
+}
+
+// Handler serves the samples of DefaultRegistry.
+func Handler() gin.HandlerFunc {
+	return HandlerFor(DefaultRegistry)
+}
+
+func HandlerFor(r *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var buf bytes.Buffer
+		if err := r.Write(&buf); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+
+			return
+		}
+
+		c.Data(http.StatusOK, ContentType, buf.Bytes())
+	}
+}
@@ -0,0 +1,318 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the default histogram buckets, in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector writes its samples in the Prometheus text exposition format.
+type Collector interface {
+	Write(w io.Writer) error
+}
+
+// Registry holds the collectors exposed by one /metrics endpoint.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry holds the HTTP and build metrics of this package.
+var DefaultRegistry = NewRegistry()
+
+func (r *Registry) MustRegister(collectors ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, collectors...)
+}
+
+// Write writes the samples of all collectors in registration order.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, c := range r.collectors {
+		if err := c.Write(bw); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+type desc struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+}
+
+func (d *desc) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.typ)
+
+	return err
+}
+
+// key joins label values, it panics when their number does not match the labels.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s wants %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs renders {a="x",b="y"}, extra is appended, e.g. le="0.5".
+func (d *desc) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+
+	for i, v := range values {
+		pairs = append(pairs, d.labels[i]+`="`+escapeLabel(v)+`"`)
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabel(extra[i+1])+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+type sample struct {
+	values []string
+	value  float64
+}
+
+// vec stores one float per label values, it backs counters and gauges.
+type vec struct {
+	desc
+	mu      sync.Mutex
+	samples map[string]*sample
+}
+
+func (v *vec) add(delta float64, values []string) {
+	k := v.key(values)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.samples[k]
+	if !ok {
+		s = &sample{values: append([]string{}, values...)}
+		v.samples[k] = s
+	}
+
+	s.value += delta
+}
+
+func (v *vec) set(value float64, values []string) {
+	k := v.key(values)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.samples[k] = &sample{values: append([]string{}, values...), value: value}
+}
+
+func (v *vec) Write(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+
+	for _, k := range sortedKeys(v.samples) {
+		s := v.samples[k]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", v.name, v.labelPairs(s.values), formatFloat(s.value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CounterVec is a monotonically increasing value per label values.
+type CounterVec struct {
+	vec
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{vec{desc: desc{name: name, help: help, typ: "counter", labels: labels}, samples: make(map[string]*sample)}}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.add(1, labelValues)
+}
+
+// Add panics when delta is negative.
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", c.name))
+	}
+
+	c.add(delta, labelValues)
+}
+
+// GaugeVec is a value per label values that can go up and down.
+type GaugeVec struct {
+	vec
+}
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{vec{desc: desc{name: name, help: help, typ: "gauge", labels: labels}, samples: make(map[string]*sample)}}
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.set(value, labelValues)
+}
+
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.add(1, labelValues)
+}
+
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.add(-1, labelValues)
+}
+
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.add(delta, labelValues)
+}
+
+type histogramSample struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec counts observations in buckets per label values.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	samples map[string]*histogramSample
+}
+
+// NewHistogramVec returns a histogram with the upper bounds buckets, DefBuckets
+// when nil.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+
+	return &HistogramVec{
+		desc:    desc{name: name, help: help, typ: "histogram", labels: labels},
+		buckets: buckets,
+		samples: make(map[string]*histogramSample),
+	}
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	k := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.samples[k]
+	if !ok {
+		s = &histogramSample{values: append([]string{}, labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.samples[k] = s
+	}
+
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) Write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.writeHeader(w); err != nil {
+		return err
+	}
+
+	for _, k := range sortedKeys(h.samples) {
+		s := h.samples[k]
+
+		cumulative := uint64(0)
+
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.values, "le", formatFloat(upper)), cumulative); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, h.labelPairs(s.values, "le", "+Inf"), s.count,
+			h.name, h.labelPairs(s.values), formatFloat(s.sum),
+			h.name, h.labelPairs(s.values), s.count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
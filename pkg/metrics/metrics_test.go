@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/neee333ko/component-base/pkg/core"
+	"github.com/neee333ko/errors"
+)
+
+func TestRegistry(t *testing.T) {
+	counter := NewCounterVec("jobs_total", "Jobs by queue.", "queue")
+	gauge := NewGaugeVec("workers", "Busy workers.\nPer pool.")
+	histogram := NewHistogramVec("job_seconds", "Job latency.", []float64{1, 0.5})
+
+	r := NewRegistry()
+	r.MustRegister(counter, gauge, histogram)
+
+	counter.Inc("b")
+	counter.Add(2, `a"\`)
+	gauge.Inc()
+	gauge.Inc()
+	gauge.Dec()
+	histogram.Observe(0.2)
+	histogram.Observe(0.7)
+	histogram.Observe(3)
+
+	want := `# HELP jobs_total Jobs by queue.
+# TYPE jobs_total counter
+jobs_total{queue="a\"\\"} 2
+jobs_total{queue="b"} 1
+# HELP workers Busy workers.\nPer pool.
+# TYPE workers gauge
+workers 1
+# HELP job_seconds Job latency.
+# TYPE job_seconds histogram
+job_seconds_bucket{le="0.5"} 1
+job_seconds_bucket{le="1"} 2
+job_seconds_bucket{le="+Inf"} 3
+job_seconds_sum 3.9
+job_seconds_count 3
+`
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil || buf.String() != want {
+		t.Errorf("Write has an error: want:\n%s\ngot:\n%s\n", want, buf.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(Middleware())
+	engine.GET("/v1/users/:name", func(c *gin.Context) {
+		if c.Param("name") == "missing" {
+			core.WriteResponse(c, errors.WithCode(core.ErrBind, "bad request"), nil)
+
+			return
+		}
+
+		core.WriteResponse(c, nil, "ok")
+	})
+	engine.GET("/metrics", Handler())
+
+	for _, target := range []string{"/v1/users/colin", "/v1/users/lex", "/v1/users/missing", "/nope"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	for _, method := range []string{"FOO", "BAR"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/nope", nil))
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := []string{
+		`http_requests_total{route="/v1/users/:name",method="GET",status="200",code=""} 2`,
+		`http_requests_total{route="/v1/users/:name",method="GET",status="400",code="990001"} 1`,
+		`http_requests_total{route="<unmatched>",method="GET",status="404",code=""} 1`,
+		`http_requests_total{route="<unmatched>",method="other",status="404",code=""} 2`,
+		`http_request_duration_seconds_count{route="/v1/users/:name",method="GET"} 3`,
+		`http_requests_in_flight{route="/v1/users/:name",method="GET"} 0`,
+		`http_requests_in_flight{route="/metrics",method="GET"} 1`,
+		`build_info{git_version="`,
+	}
+
+	if w.Header().Get("Content-Type") != ContentType {
+		t.Errorf("Handler has an error: want:%s got:%s\n", ContentType, w.Header().Get("Content-Type"))
+	}
+
+	for _, line := range want {
+		if !strings.Contains(w.Body.String(), line) {
+			t.Errorf("Handler misses %q:\n%s\n", line, w.Body.String())
+		}
+	}
+}
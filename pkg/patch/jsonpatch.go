@@ -0,0 +1,378 @@
+package patch
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+)
+
+// Operation is one operation of an RFC 6902 JSON Patch.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies the RFC 6902 JSON Patch patch to the JSON document doc.
+func ApplyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, errors.WithMessagef(ErrInvalidPatch, "decode json patch: %s", err.Error())
+	}
+
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		if target, err = applyOperation(target, op); err != nil {
+			return nil, errors.Wrapf(err, "operation %d (%s %s)", i, op.Op, op.Path)
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errors.WithMessagef(ErrInvalidPatch, "%s operation without value", op.Op)
+		}
+
+		value, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			return replace(doc, path, value)
+		}
+
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equal(current, value) {
+			return nil, errors.Errorf("test failed: %s is not equal to %s", op.Path, string(op.Value))
+		}
+
+		return doc, nil
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, errors.Errorf("cannot move %s into one of its children", op.From)
+		}
+
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+	}
+
+	return nil, errors.WithMessagef(ErrInvalidPatch, "unknown operation %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.WithMessagef(ErrInvalidPatch, "invalid json pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for i, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, errors.Errorf("path /%s does not exist", strings.Join(path[:i+1], "/"))
+			}
+
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = node[index]
+		default:
+			return nil, errors.Errorf("path /%s does not exist", strings.Join(path[:i+1], "/"))
+		}
+	}
+
+	return doc, nil
+}
+
+// modify replaces the parent of the last token of path by the result of fn.
+func modify(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	if child, err = modify(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(node)-1)
+		node[index] = child
+	}
+
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+
+			return node, nil
+		case []interface{}:
+			index := len(node)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+
+			return node, nil
+		}
+
+		return nil, errors.Errorf("cannot add %s to a scalar", token)
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+
+	return modify(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, errors.Errorf("path %s does not exist", token)
+			}
+
+			delete(node, token)
+
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(node[:index], node[index+1:]...), nil
+		}
+
+		return nil, errors.Errorf("cannot remove %s from a scalar", token)
+	})
+}
+
+func replace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modify(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, errors.Errorf("path %s does not exist", token)
+			}
+
+			node[token] = value
+
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			node[index] = value
+
+			return node, nil
+		}
+
+		return nil, errors.Errorf("cannot replace %s of a scalar", token)
+	})
+}
+
+// arrayIndex parses token as an index between 0 and last.
+func arrayIndex(token string, last int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > last || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.Errorf("invalid array index %q", token)
+	}
+
+	return index, nil
+}
+
+func decode(data []byte) (interface{}, error) {
+	var v interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&v); err != nil {
+		return nil, errors.WithMessagef(ErrInvalidPatch, "decode json: %s", err.Error())
+	}
+
+	return v, nil
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(node))
+		for k, v := range node {
+			m[k] = deepCopy(v)
+		}
+
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(node))
+		for i, v := range node {
+			s[i] = deepCopy(v)
+		}
+
+		return s
+	}
+
+	return v
+}
+
+// number is the json.Number of the json package in use.
+type number interface {
+	Int64() (int64, error)
+	Float64() (float64, error)
+}
+
+// equal compares two decoded JSON values, numbers are compared by value.
+func equal(a, b interface{}) bool {
+	an, aok := a.(number)
+	bn, bok := b.(number)
+
+	if aok && bok {
+		ai, aerr := an.Int64()
+		bi, berr := bn.Int64()
+
+		if aerr == nil && berr == nil {
+			return ai == bi
+		}
+
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+
+		return aerr == nil && berr == nil && af == bf
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,45 @@
+package patch
+
+import (
+	"github.com/neee333ko/component-base/pkg/json"
+)
+
+// ApplyMergePatch applies the RFC 7386 JSON Merge Patch patch to the JSON
+// document doc.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{}, len(p))
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+
+			continue
+		}
+
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
@@ -0,0 +1,117 @@
+package patch
+
+import (
+	"reflect"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/component-base/pkg/validation/field"
+	"github.com/neee333ko/errors"
+)
+
+// Content types of the supported patches.
+const (
+	JSONPatchType  = "application/json-patch+json"
+	MergePatchType = "application/merge-patch+json"
+)
+
+var (
+	// ErrInvalidPatch is the cause of the errors returned for malformed patches.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrUnsupportedPatchType is returned for unknown patch content types.
+	ErrUnsupportedPatchType = errors.New("unsupported patch type")
+)
+
+// Apply patches the struct pointed to by obj in place with a patch of
+// patchType, the fields removed by the patch are reset to their zero value.
+func Apply(obj interface{}, patchType string, patch []byte) error {
+	doc, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	switch patchType {
+	case JSONPatchType:
+		doc, err = ApplyJSONPatch(doc, patch)
+	case MergePatchType:
+		doc, err = ApplyMergePatch(doc, patch)
+	default:
+		return errors.WithMessage(ErrUnsupportedPatchType, patchType)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	patched := reflect.New(reflect.TypeOf(obj).Elem())
+	if err := json.Unmarshal(doc, patched.Interface()); err != nil {
+		return errors.WithMessagef(ErrInvalidPatch, "decode patched object: %s", err.Error())
+	}
+
+	restoreHidden(patched.Elem(), reflect.ValueOf(obj).Elem())
+	reflect.ValueOf(obj).Elem().Set(patched.Elem())
+
+	return nil
+}
+
+// restoreHidden copies the fields that are not serialized, e.g. `json:"-"`,
+// from src to the patched dst.
+func restoreHidden(dst, src reflect.Value) {
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() || src.IsNil() {
+			return
+		}
+
+		dst, src = dst.Elem(), src.Elem()
+	}
+
+	if dst.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < dst.NumField(); i++ {
+		f := dst.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if f.Tag.Get("json") == "-" {
+			dst.Field(i).Set(src.Field(i))
+
+			continue
+		}
+
+		restoreHidden(dst.Field(i), src.Field(i))
+	}
+}
+
+// ValidateImmutable rejects changes of the id, instanceID and createdAt of
+// the ObjectMeta of obj, old is its ObjectMeta before the patch.
+func ValidateImmutable(old *metav1.ObjectMeta, obj interface{}) field.ErrorList {
+	accessor, ok := obj.(metav1.ObjectAccessor)
+	if !ok || old == nil {
+		return nil
+	}
+
+	meta, ok := accessor.GetObject().(*metav1.ObjectMeta)
+	if !ok {
+		return nil
+	}
+
+	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("metadata")
+
+	if meta.ID != old.ID {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("id"), meta.ID, "field is immutable"))
+	}
+
+	if meta.InstanceID != old.InstanceID {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("instanceID"), meta.InstanceID, "field is immutable"))
+	}
+
+	if !meta.CreatedAt.Equal(old.CreatedAt) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("createdAt"), meta.CreatedAt, "field is immutable"))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,153 @@
+package patch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+	"github.com/neee333ko/errors"
+)
+
+func jsonEqual(a, b string) bool {
+	av, err := decode([]byte(a))
+	if err != nil {
+		return false
+	}
+
+	bv, err := decode([]byte(b))
+	if err != nil {
+		return false
+	}
+
+	return equal(av, bv)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, want: `{"baz":"qux","foo":"bar"}`},
+		{doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, want: `{"foo":["bar","qux","baz"]}`},
+		{doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, want: `{"foo":["bar",["abc","def"]]}`},
+		{doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, want: `{"foo":"bar"}`},
+		{doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, want: `{"foo":["bar","baz"]}`},
+		{doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, want: `{"baz":"boo","foo":"bar"}`},
+		{
+			doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, want: `{"foo":["all","cows","eat","grass"]}`},
+		{doc: `{"foo":{"bar":[1]}}`, patch: `[{"op":"copy","from":"/foo/bar","path":"/baz"}]`, want: `{"foo":{"bar":[1]},"baz":[1]}`},
+		{doc: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, want: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, wantErr: true},
+		{doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10},{"op":"replace","path":"/~1","value":0}]`, want: `{"/":0,"~1":10}`},
+		{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, wantErr: true},
+		{doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/2","value":"qux"}]`, wantErr: true},
+		{doc: `{"foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"qux"}]`, wantErr: true},
+		{doc: `{"foo":{"bar":1}}`, patch: `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, wantErr: true},
+		{doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz"}]`, wantErr: true},
+		{doc: `{"foo":"bar"}`, patch: `[{"op":"copy","path":"/baz","from":"/qux"}]`, wantErr: true},
+		{doc: `{"foo":"bar"}`, patch: `{"op":"add"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ApplyJSONPatch([]byte(tt.doc), []byte(tt.patch))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ApplyJSONPatch(%s, %s) has an error: want an error got:%s\n", tt.doc, tt.patch, got)
+			}
+
+			continue
+		}
+
+		if err != nil || !jsonEqual(string(got), tt.want) {
+			t.Errorf("ApplyJSONPatch(%s, %s) has an error: want:%s got:%s,%v\n", tt.doc, tt.patch, tt.want, got, err)
+		}
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{doc: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{doc: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{doc: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{doc: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{doc: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{doc: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{doc: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{doc: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		got, err := ApplyMergePatch([]byte(tt.doc), []byte(tt.patch))
+		if err != nil || !jsonEqual(string(got), tt.want) {
+			t.Errorf("ApplyMergePatch(%s, %s) has an error: want:%s got:%s,%v\n", tt.doc, tt.patch, tt.want, got, err)
+		}
+	}
+}
+
+type testUser struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Nickname          string `json:"nickname,omitempty"`
+	Password          string `json:"-"`
+}
+
+func TestApply(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		patchType string
+		patch     string
+		want      string
+		wantErrs  int
+		wantErr   error
+	}{
+		{patchType: MergePatchType, patch: `{"nickname":null,"metadata":{"name":"lex"}}`, want: "lex"},
+		{patchType: JSONPatchType, patch: `[{"op":"replace","path":"/metadata/name","value":"lex"}]`, want: "lex"},
+		{patchType: MergePatchType, patch: `{"metadata":{"id":2,"instanceID":"user-2"}}`, want: "colin", wantErrs: 2},
+		{patchType: JSONPatchType, patch: `[{"op":"remove","path":"/metadata/createdAt"}]`, want: "colin", wantErrs: 1},
+		{patchType: JSONPatchType, patch: `[{"op":"bogus","path":"/nickname"}]`, wantErr: ErrInvalidPatch},
+		{patchType: "application/json", patch: `{}`, wantErr: ErrUnsupportedPatchType},
+	}
+
+	for _, tt := range tests {
+		user := &testUser{
+			ObjectMeta: metav1.ObjectMeta{ID: 1, InstanceID: "user-1", Name: "colin", CreatedAt: created},
+			Nickname:   "col",
+			Password:   "secret",
+		}
+		old := user.ObjectMeta
+
+		err := Apply(user, tt.patchType, []byte(tt.patch))
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Apply(%s) has an error: want:%v got:%v\n", tt.patch, tt.wantErr, err)
+			}
+
+			continue
+		}
+
+		if err != nil || user.Name != tt.want || user.Password != "secret" {
+			data, _ := json.Marshal(user)
+			t.Errorf("Apply(%s) has an error: want:%s got:%s,%v\n", tt.patch, tt.want, data, err)
+		}
+
+		if errs := ValidateImmutable(&old, user); len(errs) != tt.wantErrs {
+			t.Errorf("ValidateImmutable(%s) has an error: want:%d got:%v\n", tt.patch, tt.wantErrs, errs)
+		}
+	}
+}
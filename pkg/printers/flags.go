@@ -0,0 +1,95 @@
+package printers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/neee333ko/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	OutputFormatTable          = ""
+	OutputFormatWide           = "wide"
+	OutputFormatJSON           = "json"
+	OutputFormatYAML           = "yaml"
+	OutputFormatJSONPath       = "jsonpath"
+	OutputFormatJSONPathFile   = "jsonpath-file"
+	OutputFormatGoTemplate     = "go-template"
+	OutputFormatGoTemplateFile = "go-template-file"
+)
+
+// AllowedFormats returns the values accepted by the --output flag.
+func AllowedFormats() []string {
+	return []string{
+		OutputFormatWide,
+		OutputFormatJSON,
+		OutputFormatYAML,
+		OutputFormatJSONPath + "=...",
+		OutputFormatJSONPathFile + "=...",
+		OutputFormatGoTemplate + "=...",
+		OutputFormatGoTemplateFile + "=...",
+	}
+}
+
+// PrintFlags composes the flags used to select a ResourcePrinter.
+type PrintFlags struct {
+	OutputFormat string
+	NoHeaders    bool
+}
+
+func NewPrintFlags() *PrintFlags {
+	return &PrintFlags{}
+}
+
+func (f *PrintFlags) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&f.OutputFormat, "output", "o", f.OutputFormat,
+		fmt.Sprintf("Output format. One of: (%s).", strings.Join(AllowedFormats(), ", ")))
+	fs.BoolVar(&f.NoHeaders, "no-headers", f.NoHeaders,
+		"When using the default or wide output format, don't print headers.")
+}
+
+func (f *PrintFlags) Validate() error {
+	_, err := f.ToPrinter()
+
+	return err
+}
+
+// ToPrinter returns the printer selected by the output format.
+func (f *PrintFlags) ToPrinter() (ResourcePrinter, error) {
+	format, arg, hasArg := strings.Cut(f.OutputFormat, "=")
+
+	switch format {
+	case OutputFormatTable:
+		return &TablePrinter{NoHeaders: f.NoHeaders}, nil
+	case OutputFormatWide:
+		return &TablePrinter{Wide: true, NoHeaders: f.NoHeaders}, nil
+	case OutputFormatJSON:
+		return &JSONPrinter{}, nil
+	case OutputFormatYAML:
+		return &YAMLPrinter{}, nil
+	case OutputFormatJSONPath, OutputFormatJSONPathFile, OutputFormatGoTemplate, OutputFormatGoTemplateFile:
+		if !hasArg || arg == "" {
+			return nil, errors.Errorf("%s format specified but no template given", format)
+		}
+
+		if strings.HasSuffix(format, "-file") {
+			data, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error reading template %s", arg)
+			}
+
+			arg = string(data)
+		}
+
+		if strings.HasPrefix(format, OutputFormatJSONPath) {
+			return NewJSONPathPrinter(arg)
+		}
+
+		return NewGoTemplatePrinter(arg)
+	default:
+		return nil, errors.Errorf("unable to match a printer suitable for the output format %q, allowed formats are: %s",
+			f.OutputFormat, strings.Join(AllowedFormats(), ", "))
+	}
+}
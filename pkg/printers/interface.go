@@ -0,0 +1,67 @@
+package printers
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/neee333ko/component-base/pkg/json"
+)
+
+// ResourcePrinter is an interface that knows how to print API objects.
+type ResourcePrinter interface {
+	// PrintObj formats obj and writes it to w.
+	PrintObj(obj interface{}, w io.Writer) error
+}
+
+// ResourcePrinterFunc is a function that can print objects.
+type ResourcePrinterFunc func(obj interface{}, w io.Writer) error
+
+func (fn ResourcePrinterFunc) PrintObj(obj interface{}, w io.Writer) error {
+	return fn(obj, w)
+}
+
+// toGeneric converts obj into maps, slices and scalars keyed by json names so
+// that jsonpath expressions, templates and yaml see the same field names as
+// API clients. Integral numbers are kept as int64.
+func toGeneric(obj interface{}) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	return convertNumbers(generic), nil
+}
+
+func convertNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = convertNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = convertNumbers(e)
+		}
+	case interface{ Int64() (int64, error) }:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		if f, ok := v.(interface{ Float64() (float64, error) }); ok {
+			if fv, err := f.Float64(); err == nil {
+				return fv
+			}
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,43 @@
+package printers
+
+import (
+	"io"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONPrinter is an implementation of ResourcePrinter which outputs an object as JSON.
+type JSONPrinter struct{}
+
+func (p *JSONPrinter) PrintObj(obj interface{}, w io.Writer) error {
+	data, err := json.MarshalIndent(obj, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+	_, err = w.Write(data)
+
+	return err
+}
+
+// YAMLPrinter is an implementation of ResourcePrinter which outputs an object as YAML.
+// The field names are the json names of the object.
+type YAMLPrinter struct{}
+
+func (p *YAMLPrinter) PrintObj(obj interface{}, w io.Writer) error {
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
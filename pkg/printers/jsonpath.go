@@ -0,0 +1,422 @@
+package printers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+)
+
+// JSONPathPrinter prints the results of a jsonpath template, e.g.
+// `{.name}` or `{range .items[*]}{.name}{"\n"}{end}`. Supported expressions
+// are fields, quoted fields, indexes, slices and the [*] wildcard.
+type JSONPathPrinter struct {
+	template string
+	nodes    []jsonPathNode
+}
+
+type jsonPathNode interface{}
+
+type textNode string
+
+type exprNode []segment
+
+type rangeNode struct {
+	expr exprNode
+	body []jsonPathNode
+}
+
+type segmentKind int
+
+const (
+	segmentField segmentKind = iota
+	segmentIndex
+	segmentSlice
+	segmentWildcard
+)
+
+type segment struct {
+	kind       segmentKind
+	field      string
+	index      int
+	start, end *int
+}
+
+func NewJSONPathPrinter(template string) (*JSONPathPrinter, error) {
+	if !strings.Contains(template, "{") {
+		template = "{" + template + "}"
+	}
+
+	nodes, err := parseJSONPath(template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing jsonpath %s", template)
+	}
+
+	return &JSONPathPrinter{template: template, nodes: nodes}, nil
+}
+
+func (p *JSONPathPrinter) PrintObj(obj interface{}, w io.Writer) error {
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := executeJSONPath(buf, p.nodes, generic); err != nil {
+		return errors.Wrapf(err, "error executing jsonpath %s", p.template)
+	}
+
+	_, err = buf.WriteTo(w)
+
+	return err
+}
+
+func parseJSONPath(template string) ([]jsonPathNode, error) {
+	type frame struct {
+		nodes []jsonPathNode
+		rng   *rangeNode
+	}
+
+	stack := []*frame{{}}
+
+	for len(template) > 0 {
+		top := stack[len(stack)-1]
+
+		open := strings.Index(template, "{")
+		if open == -1 {
+			top.nodes = append(top.nodes, textNode(template))
+			break
+		}
+
+		if open > 0 {
+			top.nodes = append(top.nodes, textNode(template[:open]))
+		}
+
+		closing := matchingBrace(template, open)
+		if closing == -1 {
+			return nil, errors.New("unclosed action")
+		}
+
+		action := strings.TrimSpace(template[open+1 : closing])
+		template = template[closing+1:]
+
+		switch {
+		case action == "end":
+			if top.rng == nil {
+				return nil, errors.New("not in range, nothing to end")
+			}
+
+			top.rng.body = top.nodes
+			stack = stack[:len(stack)-1]
+			parent := stack[len(stack)-1]
+			parent.nodes = append(parent.nodes, top.rng)
+		case strings.HasPrefix(action, "range "):
+			expr, err := parseExpr(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, err
+			}
+
+			stack = append(stack, &frame{rng: &rangeNode{expr: expr}})
+		case strings.HasPrefix(action, `"`):
+			text, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, errors.Errorf("invalid string literal %s", action)
+			}
+
+			top.nodes = append(top.nodes, textNode(text))
+		default:
+			expr, err := parseExpr(action)
+			if err != nil {
+				return nil, err
+			}
+
+			top.nodes = append(top.nodes, expr)
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, errors.New("range is not closed by end")
+	}
+
+	return stack[0].nodes, nil
+}
+
+// matchingBrace returns the index of the '}' closing the '{' at open, braces
+// inside quoted strings are skipped.
+func matchingBrace(s string, open int) int {
+	var quote byte
+
+	for i := open + 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func parseExpr(expr string) (exprNode, error) {
+	if expr == "" {
+		return nil, errors.New("empty expression")
+	}
+
+	segments := make(exprNode, 0)
+	expr = strings.TrimLeft(expr, "$@")
+
+	for len(expr) > 0 {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			if strings.HasPrefix(expr, ".") {
+				return nil, errors.New("recursive descent is not supported")
+			}
+
+			end := strings.IndexAny(expr, ".[")
+			if end == -1 {
+				end = len(expr)
+			}
+
+			name := expr[:end]
+			expr = expr[end:]
+
+			switch name {
+			case "":
+			case "*":
+				segments = append(segments, segment{kind: segmentWildcard})
+			default:
+				segments = append(segments, segment{kind: segmentField, field: name})
+			}
+		case '[':
+			end := strings.Index(expr, "]")
+			if end == -1 {
+				return nil, errors.Errorf("unterminated array notation %s", expr)
+			}
+
+			seg, err := parseBracket(strings.TrimSpace(expr[1:end]))
+			if err != nil {
+				return nil, err
+			}
+
+			segments = append(segments, seg)
+			expr = expr[end+1:]
+		default:
+			return nil, errors.Errorf("unrecognized expression %s", expr)
+		}
+	}
+
+	return segments, nil
+}
+
+func parseBracket(s string) (segment, error) {
+	switch {
+	case s == "*":
+		return segment{kind: segmentWildcard}, nil
+	case len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]:
+		return segment{kind: segmentField, field: s[1 : len(s)-1]}, nil
+	case strings.Contains(s, ":"):
+		parts := strings.SplitN(s, ":", 2)
+		seg := segment{kind: segmentSlice}
+
+		for i, part := range parts {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return segment{}, errors.Errorf("invalid slice %s", s)
+			}
+
+			if i == 0 {
+				seg.start = &n
+			} else {
+				seg.end = &n
+			}
+		}
+
+		return seg, nil
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return segment{}, errors.Errorf("invalid array index %s", s)
+		}
+
+		return segment{kind: segmentIndex, index: n}, nil
+	}
+}
+
+func executeJSONPath(w io.Writer, nodes []jsonPathNode, data interface{}) error {
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case textNode:
+			io.WriteString(w, string(node))
+		case exprNode:
+			values, err := node.eval(data)
+			if err != nil {
+				return err
+			}
+
+			texts := make([]string, 0, len(values))
+
+			for _, v := range values {
+				text, err := printValue(v)
+				if err != nil {
+					return err
+				}
+
+				texts = append(texts, text)
+			}
+
+			io.WriteString(w, strings.Join(texts, " "))
+		case *rangeNode:
+			values, err := node.expr.eval(data)
+			if err != nil {
+				return err
+			}
+
+			for _, v := range values {
+				elems := []interface{}{v}
+				if arr, ok := v.([]interface{}); ok {
+					elems = arr
+				}
+
+				for _, elem := range elems {
+					if err := executeJSONPath(w, node.body, elem); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// eval returns the values selected by e, it fails when a field is missing
+// from an object.
+func (e exprNode) eval(data interface{}) ([]interface{}, error) {
+	values := []interface{}{data}
+
+	for _, seg := range e {
+		next := make([]interface{}, 0, len(values))
+
+		for _, v := range values {
+			selected, err := seg.apply(v)
+			if err != nil {
+				return nil, err
+			}
+
+			next = append(next, selected...)
+		}
+
+		values = next
+	}
+
+	return values, nil
+}
+
+func (s segment) apply(v interface{}) ([]interface{}, error) {
+	switch s.kind {
+	case segmentField:
+		if m, ok := v.(map[string]interface{}); ok {
+			value, ok := m[s.field]
+			if !ok {
+				return nil, errors.Errorf("%s is not found", s.field)
+			}
+
+			return []interface{}{value}, nil
+		}
+	case segmentIndex:
+		if arr, ok := v.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(arr)
+			}
+
+			if i >= 0 && i < len(arr) {
+				return []interface{}{arr[i]}, nil
+			}
+		}
+	case segmentSlice:
+		if arr, ok := v.([]interface{}); ok {
+			start, end := 0, len(arr)
+			if s.start != nil {
+				start = clampIndex(*s.start, len(arr))
+			}
+
+			if s.end != nil {
+				end = clampIndex(*s.end, len(arr))
+			}
+
+			if start < end {
+				return arr[start:end], nil
+			}
+		}
+	case segmentWildcard:
+		switch v := v.(type) {
+		case []interface{}:
+			return v, nil
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+
+			// print objects in a stable order
+			sort.Strings(keys)
+
+			result := make([]interface{}, 0, len(v))
+			for _, k := range keys {
+				result = append(result, v[k])
+			}
+
+			return result, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+
+	switch {
+	case i < 0:
+		return 0
+	case i > n:
+		return n
+	default:
+		return i
+	}
+}
+
+func printValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+
+		return string(data), err
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,215 @@
+package printers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+)
+
+type testSecret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Username          string `json:"username"`
+	Expires           int64  `json:"expires"`
+}
+
+type testSecretList struct {
+	metav1.ListMeta `json:",inline"`
+	Items           []*testSecret `json:"items"`
+}
+
+func newTestSecretList() *testSecretList {
+	created := time.Now().Add(-90 * time.Minute)
+
+	return &testSecretList{
+		ListMeta: metav1.ListMeta{TotalCount: 2},
+		Items: []*testSecret{
+			{
+				TypeMeta:   metav1.TypeMeta{Type: "Secret"},
+				ObjectMeta: metav1.ObjectMeta{Name: "secret-a", InstanceID: "secret-abc", CreatedAt: created},
+				Username:   "admin",
+				Expires:    1700000000,
+			},
+			{
+				TypeMeta:   metav1.TypeMeta{Type: "Secret"},
+				ObjectMeta: metav1.ObjectMeta{Name: "secret-b", InstanceID: "secret-def", CreatedAt: created},
+				Username:   "colin",
+			},
+		},
+	}
+}
+
+func init() {
+	metav1.RegisterTableColumns("Secret", metav1.TableColumn{
+		TableColumnDefinition: metav1.TableColumnDefinition{Name: "Username", Type: "string"},
+		Value:                 func(obj interface{}) interface{} { return obj.(*testSecret).Username },
+	})
+}
+
+func TestPrinters(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    []string
+		notWant []string
+	}{
+		{
+			output:  "",
+			want:    []string{"NAME", "USERNAME", "AGE", "secret-a", "admin", "90m"},
+			notWant: []string{"INSTANCEID"},
+		},
+		{
+			output: "wide",
+			want:   []string{"INSTANCEID", "secret-def"},
+		},
+		{
+			output: "json",
+			want:   []string{`"totalCount": 2`, `"expires": 1700000000`},
+		},
+		{
+			output: "yaml",
+			want:   []string{"totalCount: 2", "expires: 1700000000", "name: secret-a"},
+		},
+		{
+			output: `jsonpath={range .items[*]}{.metadata.name}:{.username}{"\n"}{end}`,
+			want:   []string{"secret-a:admin\nsecret-b:colin\n"},
+		},
+		{
+			output: "jsonpath={.items[-1].metadata.instanceID}",
+			want:   []string{"secret-def"},
+		},
+		{
+			output: "go-template={{range .items}}{{.metadata.name}} {{end}}",
+			want:   []string{"secret-a secret-b "},
+		},
+	}
+
+	for _, tt := range tests {
+		flags := &PrintFlags{OutputFormat: tt.output}
+
+		printer, err := flags.ToPrinter()
+		if err != nil {
+			t.Fatalf("ToPrinter(%s) has an error: %v\n", tt.output, err)
+		}
+
+		buf := bytes.NewBuffer(nil)
+		if err := printer.PrintObj(newTestSecretList(), buf); err != nil {
+			t.Fatalf("PrintObj(%s) has an error: %v\n", tt.output, err)
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("PrintObj(%s) misses %q:\n%s\n", tt.output, want, buf.String())
+			}
+		}
+
+		for _, notWant := range tt.notWant {
+			if strings.Contains(buf.String(), notWant) {
+				t.Errorf("PrintObj(%s) should not contain %q:\n%s\n", tt.output, notWant, buf.String())
+			}
+		}
+	}
+}
+
+func TestJSONPathPrinter(t *testing.T) {
+	obj := map[string]interface{}{
+		"labels": map[string]interface{}{"c": 3, "a": 1, "b": 2, "e": 5, "d": 4},
+	}
+
+	tests := []struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{template: "{.labels[*]}", want: "1 2 3 4 5"},
+		{template: "{.labels.a}", want: "1"},
+		{template: "{.labels.x}", wantErr: true},
+		{template: "{.spec.name}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		p, err := NewJSONPathPrinter(tt.template)
+		if err != nil {
+			t.Fatalf("NewJSONPathPrinter(%s) has an error: %v\n", tt.template, err)
+		}
+
+		// map iteration order is random, repeat to catch unstable output
+		for i := 0; i < 10; i++ {
+			buf := bytes.NewBuffer(nil)
+
+			err := p.PrintObj(obj, buf)
+			if (err != nil) != tt.wantErr || buf.String() != tt.want {
+				t.Errorf("PrintObj(%s) has an error: want:%q,%v got:%q,%v\n", tt.template, tt.want, tt.wantErr, buf.String(), err)
+
+				break
+			}
+		}
+	}
+}
+
+func TestPrintFlagsValidate(t *testing.T) {
+	tests := []struct {
+		output  string
+		wantErr bool
+	}{
+		{output: "wide"},
+		{output: "jsonpath={.name}"},
+		{output: "jsonpath", wantErr: true},
+		{output: "jsonpath={range .items[*]}", wantErr: true},
+		{output: "go-template={{.name", wantErr: true},
+		{output: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := (&PrintFlags{OutputFormat: tt.output}).Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s) has an error: want error:%v got:%v\n", tt.output, tt.wantErr, err)
+		}
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	table := metav1.ConvertToTable(newTestSecretList(), nil)
+
+	tests := []struct {
+		printer *TablePrinter
+		want    []string
+		notWant []string
+	}{
+		{
+			printer: &TablePrinter{},
+			want:    []string{"NAME", "USERNAME", "AGE", "secret-a", "admin", "90m"},
+			notWant: []string{"INSTANCEID"},
+		},
+		{
+			printer: &TablePrinter{Wide: true},
+			want:    []string{"INSTANCEID", "secret-def"},
+		},
+		{
+			printer: &TablePrinter{NoHeaders: true},
+			want:    []string{"secret-b"},
+			notWant: []string{"NAME"},
+		},
+	}
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		if err := tt.printer.PrintObj(table, buf); err != nil {
+			t.Fatalf("PrintObj(%+v) has an error: %v\n", tt.printer, err)
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("PrintObj(%+v) misses %q:\n%s\n", tt.printer, want, buf.String())
+			}
+		}
+
+		for _, notWant := range tt.notWant {
+			if strings.Contains(buf.String(), notWant) {
+				t.Errorf("PrintObj(%+v) should not contain %q:\n%s\n", tt.printer, notWant, buf.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	metav1 "github.com/neee333ko/component-base/pkg/meta/v1"
+)
+
+// TablePrinter prints a metav1.Table, or objects and the items of a list
+// converted by metav1.ConvertToTable with the columns registered by
+// metav1.RegisterTableColumns.
+type TablePrinter struct {
+	Wide      bool
+	NoHeaders bool
+}
+
+func (p *TablePrinter) PrintObj(obj interface{}, w io.Writer) error {
+	t, ok := obj.(*metav1.Table)
+	if !ok {
+		t = metav1.ConvertToTable(obj, nil)
+	}
+
+	return p.printTable(t, w)
+}
+
+// printTable prints the rows of t, priority columns are only printed with
+// -o wide.
+func (p *TablePrinter) printTable(t *metav1.Table, w io.Writer) error {
+	if len(t.Rows) == 0 {
+		return nil
+	}
+
+	visible := make([]int, 0, len(t.ColumnDefinitions))
+
+	headers := make([]interface{}, 0, len(t.ColumnDefinitions))
+	for i, col := range t.ColumnDefinitions {
+		if col.Priority > 0 && !p.Wide {
+			continue
+		}
+
+		visible = append(visible, i)
+		headers = append(headers, strings.ToUpper(col.Name))
+	}
+
+	table := uitable.New()
+	table.Separator = "   "
+
+	if !p.NoHeaders && len(headers) > 0 {
+		table.AddRow(headers...)
+	}
+
+	for _, row := range t.Rows {
+		cells := make([]interface{}, 0, len(visible))
+
+		if len(visible) == 0 {
+			cells = append(cells, row.Cells...)
+		}
+
+		for _, i := range visible {
+			if i < len(row.Cells) {
+				cells = append(cells, cell(row.Cells[i]))
+			}
+		}
+
+		table.AddRow(cells...)
+	}
+
+	_, err := fmt.Fprintln(w, table.String())
+
+	return err
+}
+
+// cell prints empty values as <none>.
+func cell(v interface{}) string {
+	if v == nil || v == "" {
+		return "<none>"
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,48 @@
+package printers
+
+import (
+	"bytes"
+	"io"
+	"text/template"
+
+	"github.com/neee333ko/component-base/pkg/json"
+	"github.com/neee333ko/errors"
+)
+
+// GoTemplatePrinter is an implementation of ResourcePrinter which formats data
+// with a Go template. Fields are accessed by their json names, e.g. {{.name}}.
+type GoTemplatePrinter struct {
+	rawTemplate string
+	template    *template.Template
+}
+
+func NewGoTemplatePrinter(tmpl string) (*GoTemplatePrinter, error) {
+	t, err := template.New("output").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+
+			return string(data), err
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing template %s", tmpl)
+	}
+
+	return &GoTemplatePrinter{rawTemplate: tmpl, template: t}, nil
+}
+
+func (p *GoTemplatePrinter) PrintObj(obj interface{}, w io.Writer) error {
+	generic, err := toGeneric(obj)
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := p.template.Execute(buf, generic); err != nil {
+		return errors.Wrapf(err, "error executing template %s", p.rawTemplate)
+	}
+
+	_, err = buf.WriteTo(w)
+
+	return err
+}
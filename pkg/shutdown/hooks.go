@@ -0,0 +1,42 @@
+package shutdown
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/jinzhu/gorm"
+)
+
+// HTTPServerHook gracefully shuts down srv, waiting for active connections.
+func HTTPServerHook(srv *http.Server) HookFunc {
+	return srv.Shutdown
+}
+
+// GormHook closes the connection pool of db.
+func GormHook(db *gorm.DB) HookFunc {
+	return func(ctx context.Context) error {
+		return db.Close()
+	}
+}
+
+// WorkerHook stops background workers by calling cancel and waits for wg.
+func WorkerHook(cancel context.CancelFunc, wg *sync.WaitGroup) HookFunc {
+	return func(ctx context.Context) error {
+		cancel()
+
+		done := make(chan struct{})
+
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package shutdown
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/neee333ko/errors"
+	"github.com/neee333ko/log"
+)
+
+const DefaultHookTimeout = 30 * time.Second
+
+// Suggested orders, lower orders are shut down first.
+const (
+	OrderReadiness = 50
+	OrderServer    = 100
+	OrderWorker    = 200
+	OrderStorage   = 300
+)
+
+// HookFunc releases a component, it should return when ctx is done.
+type HookFunc func(ctx context.Context) error
+
+type hook struct {
+	name    string
+	order   int
+	timeout time.Duration
+	fn      HookFunc
+}
+
+// Manager runs the registered shutdown hooks in ascending order. Hooks sharing
+// an order run concurrently.
+type Manager struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	hooks   []hook
+
+	once sync.Once
+	err  error
+}
+
+func NewManager(defaultTimeout time.Duration) *Manager {
+	if defaultTimeout <= 0 {
+		defaultTimeout = DefaultHookTimeout
+	}
+
+	return &Manager{timeout: defaultTimeout}
+}
+
+// Register adds a hook, a non-positive timeout means the default timeout.
+func (m *Manager) Register(name string, order int, timeout time.Duration, fn HookFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if timeout <= 0 {
+		timeout = m.timeout
+	}
+
+	m.hooks = append(m.hooks, hook{name: name, order: order, timeout: timeout, fn: fn})
+}
+
+// Run blocks until ctx is done, e.g. the context of SetupSignalContext, then
+// shuts down all components.
+func (m *Manager) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	return m.Shutdown(context.Background())
+}
+
+// Shutdown runs the hooks once and returns the aggregate of their errors,
+// later calls return the same result.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		m.err = m.shutdown(ctx)
+	})
+
+	return m.err
+}
+
+func (m *Manager) shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := make([]hook, len(m.hooks))
+	copy(hooks, m.hooks)
+	m.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].order < hooks[j].order })
+
+	errs := make([]error, 0)
+
+	for start := 0; start < len(hooks); {
+		end := start
+		for end < len(hooks) && hooks[end].order == hooks[start].order {
+			end++
+		}
+
+		groupErrs := make([]error, end-start)
+
+		var wg sync.WaitGroup
+
+		for i := start; i < end; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				groupErrs[i-start] = runHook(ctx, hooks[i])
+			}(i)
+		}
+
+		wg.Wait()
+
+		errs = append(errs, groupErrs...)
+		start = end
+	}
+
+	return errors.NewAggregate(errs)
+}
+
+func runHook(ctx context.Context, h hook) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	log.Infof("shutting down %s\n", h.name)
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Errorf("shutdown %s failed: %s\n", h.name, err.Error())
+
+			return errors.Wrapf(err, "shutdown %s", h.name)
+		}
+
+		return nil
+	case <-ctx.Done():
+		log.Errorf("shutdown %s timed out after %s\n", h.name, h.timeout)
+
+		return errors.Errorf("shutdown %s timed out after %s", h.name, h.timeout)
+	}
+}
@@ -0,0 +1,59 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManagerShutdown(t *testing.T) {
+	m := NewManager(time.Second)
+
+	var mu sync.Mutex
+	order := make([]string, 0)
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		order = append(order, name)
+	}
+
+	m.Register("db", OrderStorage, 0, func(ctx context.Context) error {
+		record("db")
+
+		return errors.New("connection refused")
+	})
+	m.Register("http", OrderServer, 0, func(ctx context.Context) error {
+		record("http")
+
+		return nil
+	})
+	m.Register("worker", OrderWorker, 10*time.Millisecond, func(ctx context.Context) error {
+		record("worker")
+		<-make(chan struct{})
+
+		return nil
+	})
+
+	err := m.Shutdown(context.Background())
+	if err == nil {
+		t.Fatalf("Shutdown should return the hook errors\n")
+	}
+
+	for _, want := range []string{"shutdown db: connection refused", "shutdown worker timed out"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Shutdown error misses %q: %v\n", want, err)
+		}
+	}
+
+	if strings.Join(order, ",") != "http,worker,db" {
+		t.Errorf("Shutdown order has an error: want:http,worker,db got:%v\n", order)
+	}
+
+	if again := m.Shutdown(context.Background()); again == nil || len(order) != 3 {
+		t.Errorf("Shutdown should run hooks only once\n")
+	}
+}
@@ -0,0 +1,39 @@
+package shutdown
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var (
+	onlyOneSignalHandler = make(chan struct{})
+	shutdownSignals      = []os.Signal{os.Interrupt, syscall.SIGTERM}
+)
+
+// SetupSignalContext returns a context which is canceled on SIGINT or SIGTERM.
+// The program is terminated with exit code 1 if a second signal is caught.
+// Only one of SetupSignalContext and SetupSignalHandler can be called, and only once.
+func SetupSignalContext() context.Context {
+	close(onlyOneSignalHandler) // panics when called twice
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, shutdownSignals...)
+
+	go func() {
+		<-c
+		cancel()
+		<-c
+		os.Exit(1) // second signal. Exit directly.
+	}()
+
+	return ctx
+}
+
+// SetupSignalHandler is the channel form of SetupSignalContext.
+func SetupSignalHandler() <-chan struct{} {
+	return SetupSignalContext().Done()
+}
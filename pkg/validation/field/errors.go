@@ -22,6 +22,11 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.path.String(), e.ErrorBody())
 }
 
+func (e *Error) Type() TypeError       { return e.typeError }
+func (e *Error) Field() string         { return e.path.String() }
+func (e *Error) BadValue() interface{} { return e.value }
+func (e *Error) Detail() string        { return e.detail }
+
 func (e *Error) ErrorBody() string {
 	var s string
 	switch e.typeError {
@@ -4,11 +4,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
+	"github.com/go-playground/locales"
 	english "github.com/go-playground/locales/en"
+	chinese "github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/go-playground/validator/v10/translations/en"
+	"github.com/go-playground/validator/v10/translations/zh"
 	"github.com/neee333ko/component-base/pkg/validation/field"
 )
 
@@ -16,12 +21,74 @@ const (
 	DescriptionMaxLen = 256
 )
 
+// DefaultLocale is used when none of the requested locales is supported.
+const DefaultLocale = "en"
+
 type Validator struct {
 	val   *validator.Validate
 	data  interface{}
+	uni   *ut.UniversalTranslator
 	trans ut.Translator
 }
 
+type translation struct {
+	tag         string
+	translation string
+}
+
+type localeTranslations struct {
+	locale   locales.Translator
+	register func(v *validator.Validate, trans ut.Translator) error
+	regs     []translation
+}
+
+var translations = []localeTranslations{
+	{
+		locale:   english.New(),
+		register: en.RegisterDefaultTranslations,
+		regs: []translation{
+			{
+				tag:         "dir",
+				translation: "{0} must point to an existing dir, but found {1}",
+			},
+			{
+				tag:         "file",
+				translation: "{0} must point to an existing file, but found {1}",
+			},
+			{
+				tag:         "description",
+				translation: fmt.Sprintf("must be not more than %d", DescriptionMaxLen),
+			},
+			{
+				tag:         "name",
+				translation: "is not a valid name",
+			},
+		},
+	},
+	{
+		locale:   chinese.New(),
+		register: zh.RegisterDefaultTranslations,
+		regs: []translation{
+			{
+				tag:         "dir",
+				translation: "{0}必须指向一个已存在的目录，但实际为{1}",
+			},
+			{
+				tag:         "file",
+				translation: "{0}必须指向一个已存在的文件，但实际为{1}",
+			},
+			{
+				tag:         "description",
+				translation: fmt.Sprintf("长度不能超过%d", DescriptionMaxLen),
+			},
+			{
+				tag:         "name",
+				translation: "不是一个合法的名称",
+			},
+		},
+	},
+}
+
 func NewValidator(data interface{}) *Validator {
 	val := validator.New()
 
@@ -29,56 +96,65 @@ func NewValidator(data interface{}) *Validator {
 	val.RegisterValidation("file", ValidateFile, true)
 	val.RegisterValidation("description", ValidateDescription, true)
 	val.RegisterValidation("name", ValidateName, true)
+	val.RegisterTagNameFunc(jsonName)
 
 	e := english.New()
 
 	utInstance := ut.New(e, e)
 
-	t, _ := utInstance.GetTranslator("en")
-
-	err := en.RegisterDefaultTranslations(val, t)
-	if err != nil {
-		panic(err)
-	}
+	for _, lt := range translations {
+		if lt.locale.Locale() != e.Locale() {
+			if err := utInstance.AddTranslator(lt.locale, false); err != nil {
+				panic(err)
+			}
+		}
 
-	regs := []struct {
-		tag         string
-		translation string
-	}{
-		{
-			tag:         "dir",
-			translation: "{0} must point to an existing dir, but found {1}",
-		},
-		{
-			tag:         "file",
-			translation: "{0} must point to an existing file, but found {1}",
-		},
-		{
-			tag:         "description",
-			translation: fmt.Sprintf("must be not more than %d", DescriptionMaxLen),
-		},
-		{
-			tag:         "name",
-			translation: "is not a valid name",
-		},
-	}
+		t, _ := utInstance.GetTranslator(lt.locale.Locale())
 
-	for _, r := range regs {
-		err := val.RegisterTranslation(r.tag, t, registerFn(r.tag, r.translation), translationFn)
+		err := lt.register(val, t)
 		if err != nil {
 			panic(err)
 		}
+
+		for _, r := range lt.regs {
+			err := val.RegisterTranslation(r.tag, t, registerFn(r.tag, r.translation), translationFn)
+			if err != nil {
+				panic(err)
+			}
+		}
 	}
 
+	t, _ := utInstance.GetTranslator(DefaultLocale)
+
 	validator := &Validator{
 		val:   val,
 		data:  data,
+		uni:   utInstance,
 		trans: t,
 	}
 
 	return validator
 }
 
+// WithLocale translates the validation messages into the first supported
+// locale, e.g. "zh" or "zh_CN", English is used if none is supported.
+func (v *Validator) WithLocale(locales ...string) *Validator {
+	candidates := make([]string, 0, len(locales)*2)
+
+	for _, locale := range locales {
+		locale = strings.ReplaceAll(locale, "-", "_")
+		candidates = append(candidates, locale)
+
+		if i := strings.Index(locale, "_"); i > 0 {
+			candidates = append(candidates, locale[:i])
+		}
+	}
+
+	v.trans, _ = v.uni.FindTranslator(candidates...)
+
+	return v
+}
+
 func translationFn(ut ut.Translator, fe validator.FieldError) string {
 	res, err := ut.T(fe.Tag(), fe.Field(), fe.Value().(string))
 
@@ -110,16 +186,34 @@ func (v *Validator) Validate() field.ErrorList {
 
 	errlist := field.ErrorList{}
 
+	// namespaces start with the name of the validated type, if it has one
+	prefix := ""
+	if name := reflect.Indirect(reflect.ValueOf(v.data)).Type().Name(); name != "" {
+		prefix = name + "."
+	}
+
 	var validationErrs validator.ValidationErrors
 	if errors.As(err, &validationErrs) {
 		for _, e := range validationErrs {
-			errlist = append(errlist, field.Invalid(field.NewPath(e.Namespace()), e.Translate(v.trans), ""))
+			path := strings.TrimPrefix(e.Namespace(), prefix)
+			errlist = append(errlist, field.Invalid(field.NewPath(path), e.Value(), e.Translate(v.trans)))
 		}
 	}
 
 	return errlist
 }
 
+// jsonName names fields by their json tags so that error paths match the
+// request body.
+func jsonName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
 func ValidateDir(fl validator.FieldLevel) bool {
 	dir := fl.Field().String()
 
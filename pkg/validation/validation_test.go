@@ -54,3 +54,30 @@ func TestValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateWithLocale(t *testing.T) {
+	st := &struct {
+		Metadata struct {
+			Name string `json:"name" validate:"name"`
+		} `json:"metadata"`
+	}{}
+	st.Metadata.Name = "-invalid.name"
+
+	tests := []struct {
+		locales []string
+		want    string
+	}{
+		{locales: nil, want: "is not a valid name"},
+		{locales: []string{"zh-CN"}, want: "不是一个合法的名称"},
+		{locales: []string{"fr", "zh"}, want: "不是一个合法的名称"},
+		{locales: []string{"fr"}, want: "is not a valid name"},
+	}
+
+	for _, tt := range tests {
+		errlist := NewValidator(st).WithLocale(tt.locales...).Validate()
+
+		if len(errlist) != 1 || errlist[0].Detail() != tt.want || errlist[0].BadValue() != "-invalid.name" || errlist[0].Field() != "metadata.name" {
+			t.Errorf("WithLocale(%v) has an error: want:%s got:%v\n", tt.locales, tt.want, errlist)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var semverRegexp *regexp.Regexp = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)([-+].*)?$`)
+
+// Semantic is the major.minor.patch part of a semantic version, pre-release and
+// build metadata are dropped.
+type Semantic struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+}
+
+func ParseSemantic(s string) (*Semantic, error) {
+	parts := semverRegexp.FindStringSubmatch(s)
+	if parts == nil {
+		return nil, fmt.Errorf("%q is not a semantic version", s)
+	}
+
+	v := &Semantic{}
+	v.Major, _ = strconv.ParseUint(parts[1], 10, 64)
+	v.Minor, _ = strconv.ParseUint(parts[2], 10, 64)
+	v.Patch, _ = strconv.ParseUint(parts[3], 10, 64)
+
+	return v, nil
+}
+
+func MustParseSemantic(s string) *Semantic {
+	v, err := ParseSemantic(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to or greater than o.
+func (v *Semantic) Compare(o *Semantic) int {
+	pairs := [][2]uint64{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}}
+
+	for _, p := range pairs {
+		switch {
+		case p[0] < p[1]:
+			return -1
+		case p[0] > p[1]:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (v *Semantic) AtLeast(o *Semantic) bool {
+	return v.Compare(o) >= 0
+}
+
+func (v *Semantic) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
@@ -0,0 +1,26 @@
+package version
+
+import "testing"
+
+func TestParseSemantic(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "v1.2.3", b: "1.2.3", want: 0},
+		{a: "v1.10.0", b: "v1.9.9", want: 1},
+		{a: "v0.0.0-master+$Format:%h$", b: "v0.0.1", want: -1},
+	}
+
+	for _, tt := range tests {
+		a, err := ParseSemantic(tt.a)
+		if err != nil {
+			t.Fatalf("ParseSemantic has an error: %v\n", err)
+		}
+
+		if res := a.Compare(MustParseSemantic(tt.b)); res != tt.want {
+			t.Errorf("Compare has an error: want:%d got:%d\n", tt.want, res)
+		}
+	}
+}